@@ -0,0 +1,293 @@
+/*  api_client.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 16:10
+ */
+
+package mimir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultPageParam = "page"
+	DefaultSizeParam = "size"
+)
+
+type (
+	// APIClientOpts configures an APIClient, Client defaults to NewClient
+	// without any layer.
+	APIClientOpts struct {
+		BaseURL   string
+		Client    *http.Client
+		Header    http.Header
+		PageParam string
+		SizeParam string
+	}
+
+	// APIResult is the envelope of a response without its data.
+	APIResult struct {
+		StatusCode int
+		Version    Version
+		Meta       []Meta
+		Pagination *Pagination
+	}
+
+	// APIClient calls services answering with the Respond envelope.
+	APIClient struct {
+		opts   APIClientOpts
+		client *http.Client
+	}
+)
+
+// APIError is an error response, Code, Type and Message come from its
+// first Meta entry.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Type       string
+	Message    string
+	Meta       []Meta
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+// envelope mirrors Respond with the parts decoded lazily,
+// an empty Meta or Pagination is an empty object.
+type envelope struct {
+	Version    Version         `json:"version"`
+	Meta       json.RawMessage `json:"meta"`
+	Data       json.RawMessage `json:"data"`
+	Pagination json.RawMessage `json:"pagination"`
+}
+
+func NewAPIClient(opts APIClientOpts) *APIClient {
+	if opts.PageParam == "" {
+		opts.PageParam = DefaultPageParam
+	}
+	if opts.SizeParam == "" {
+		opts.SizeParam = DefaultSizeParam
+	}
+	client := opts.Client
+	if client == nil {
+		client = NewClient(ClientOpts{})
+	}
+	return &APIClient{opts: opts, client: client}
+}
+
+// Get decodes the data of the response into data.
+func (c *APIClient) Get(ctx context.Context, path string, data interface{}) (*APIResult, error) {
+	return c.Do(ctx, http.MethodGet, path, nil, data)
+}
+
+// Post sends body as JSON and decodes the data of the response into data.
+func (c *APIClient) Post(ctx context.Context, path string, body, data interface{}) (*APIResult, error) {
+	return c.Do(ctx, http.MethodPost, path, body, data)
+}
+
+// Do sends body as JSON, nil sends no body, and decodes the data of the
+// response into data, nil skips it. An error response is returned as *APIError.
+func (c *APIClient) Do(ctx context.Context, method, path string, body, data interface{}) (*APIResult, error) {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path), reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.opts.Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	req.Header.Set("Accept", string(ApplicationJSON))
+	if body != nil {
+		req.Header.Set("Content-Type", string(ApplicationJSON))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	buf, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return decodeEnvelope(resp.StatusCode, buf, data)
+}
+
+func (c *APIClient) url(path string) string {
+	if c.opts.BaseURL == "" {
+		return path
+	}
+	return strings.TrimRight(c.opts.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+func decodeEnvelope(status int, buf []byte, data interface{}) (*APIResult, error) {
+	result := &APIResult{StatusCode: status}
+
+	var env envelope
+	if err := json.Unmarshal(buf, &env); err != nil {
+		if status >= http.StatusBadRequest {
+			// not an envelope, e.g. an error page of a proxy
+			return result, &APIError{
+				StatusCode: status,
+				Code:       strconv.Itoa(status),
+				Type:       statusType(status),
+				Message:    strings.TrimSpace(string(buf)),
+			}
+		}
+		return result, fmt.Errorf("decode response envelope: %w", err)
+	}
+	result.Version = env.Version
+
+	metas, err := decodeMeta(env.Meta)
+	if err != nil {
+		return result, err
+	}
+	result.Meta = metas
+
+	if status >= http.StatusBadRequest {
+		apiErr := &APIError{StatusCode: status, Code: strconv.Itoa(status), Type: statusType(status), Meta: metas}
+		if len(metas) > 0 {
+			apiErr.Code, apiErr.Type, apiErr.Message = metas[0].Code, metas[0].Type, metas[0].Message
+		}
+		return result, apiErr
+	}
+
+	if !isEmptyJSON(env.Pagination) {
+		var page Pagination
+		if err := json.Unmarshal(env.Pagination, &page); err != nil {
+			return result, fmt.Errorf("decode response pagination: %w", err)
+		}
+		result.Pagination = &page
+	}
+
+	if data != nil && !isEmptyJSON(env.Data) {
+		if err := json.Unmarshal(env.Data, data); err != nil {
+			return result, fmt.Errorf("decode response data: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// decodeMeta accepts a single Meta as well as a list of them.
+func decodeMeta(raw json.RawMessage) ([]Meta, error) {
+	if isEmptyJSON(raw) {
+		return nil, nil
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		var metas []Meta
+		if err := json.Unmarshal(raw, &metas); err != nil {
+			return nil, fmt.Errorf("decode response meta: %w", err)
+		}
+		return metas, nil
+	}
+	var meta Meta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("decode response meta: %w", err)
+	}
+	return []Meta{meta}, nil
+}
+
+// statusType is the error type of the status, unknown to statusMap
+// when it comes from a proxy.
+func statusType(code int) string {
+	if _, ok := statusMap[code]; ok {
+		return StatusCode(code)
+	}
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(code), " ", "_"))
+}
+
+func isEmptyJSON(raw json.RawMessage) bool {
+	s := string(bytes.TrimSpace(raw))
+	return s == "" || s == "null" || s == "{}"
+}
+
+// Pages iterates over every page of a paginated endpoint.
+//
+//	pages := client.Pages(ctx, "/bookings", 50)
+//	for pages.Next(&bookings) {
+//		...
+//	}
+//	if err := pages.Err(); err != nil {
+//		...
+//	}
+func (c *APIClient) Pages(ctx context.Context, path string, size int) *PageIterator {
+	return &PageIterator{client: c, ctx: ctx, path: path, size: size}
+}
+
+// PageIterator fetches the pages one at a time.
+type PageIterator struct {
+	client  *APIClient
+	ctx     context.Context
+	path    string
+	size    int
+	current Pagination
+	done    bool
+	err     error
+}
+
+// Next fetches the next page into data, it returns false once every page
+// was read or on error.
+func (it *PageIterator) Next(data interface{}) bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	u, err := url.Parse(it.path)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	query := u.Query()
+	query.Set(it.client.opts.PageParam, strconv.Itoa(it.current.Page+1))
+	if it.size > 0 {
+		query.Set(it.client.opts.SizeParam, strconv.Itoa(it.size))
+	}
+	u.RawQuery = query.Encode()
+
+	result, err := it.client.Get(it.ctx, u.String(), data)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if result.Pagination == nil {
+		// not paginated, a single page
+		it.done = true
+		return true
+	}
+	previous := it.current.Page
+	it.current = *result.Pagination
+	if it.current.Page <= previous || it.current.Size <= 0 || it.current.Page*it.current.Size >= it.current.Total {
+		it.done = true
+	}
+	return true
+}
+
+// Page returns the pagination of the last fetched page.
+func (it *PageIterator) Page() Pagination {
+	return it.current
+}
+
+func (it *PageIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,127 @@
+/*  api_client_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 16:40
+ */
+
+package mimir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type booking struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func bookingServer() *httptest.Server {
+	bookings := make([]booking, 0, 5)
+	for i := 1; i <= 5; i++ {
+		bookings = append(bookings, booking{ID: i, Name: fmt.Sprintf("booking %d", i)})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bookings", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		if r.URL.Query().Get("status") != "paid" {
+			Response(r).APIStatusBadRequest(w, r, fmt.Errorf("status is required")).WriteJSON()
+			return
+		}
+		from, to := (page-1)*size, page*size
+		if to > len(bookings) {
+			to = len(bookings)
+		}
+		resp := Response(r)
+		resp.Body(bookings[from:to])
+		resp.Page(Pagination{Page: page, Size: size, Total: len(bookings)})
+		resp.APIStatusSuccess(w, r).WriteJSON()
+	})
+	mux.HandleFunc("/bookings/1", func(w http.ResponseWriter, r *http.Request) {
+		resp := Response(r)
+		resp.Body(bookings[0])
+		resp.APIStatusSuccess(w, r).WriteJSON()
+	})
+	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		resp := Response(r)
+		resp.Errors(
+			Meta{Code: "422", Type: "UNPROCESSABLE_ENTITY", Message: "quantity must be positive"},
+			Meta{Code: "422", Type: "UNPROCESSABLE_ENTITY", Message: "item is unknown"},
+		)
+		Status(w, r, StatusUnProcess, resp).WriteJSON()
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestAPIClientGet(t *testing.T) {
+	server := bookingServer()
+	defer server.Close()
+	client := NewAPIClient(APIClientOpts{BaseURL: server.URL})
+
+	var b booking
+	result, err := client.Get(context.Background(), "/bookings/1", &b)
+	assert.NoError(t, err)
+	assert.Equal(t, booking{ID: 1, Name: "booking 1"}, b)
+	assert.Equal(t, StatusSuccess, result.StatusCode)
+	assert.Equal(t, []Meta{{Code: StatusText(StatusSuccess)}}, result.Meta)
+	assert.Equal(t, "v1", result.Version.Label)
+	assert.Nil(t, result.Pagination)
+}
+
+func TestAPIClientErrors(t *testing.T) {
+	server := bookingServer()
+	defer server.Close()
+	client := NewAPIClient(APIClientOpts{BaseURL: server.URL})
+
+	_, err := client.Get(context.Background(), "/bookings", nil)
+	var apiErr *APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, strconv.Itoa(StatusBadRequest), apiErr.Code)
+		assert.Contains(t, apiErr.Message, "status is required")
+	}
+
+	_, err = client.Post(context.Background(), "/orders", map[string]int{"quantity": -1}, nil)
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, StatusUnProcess, apiErr.StatusCode)
+		assert.Equal(t, "quantity must be positive", apiErr.Message)
+		assert.Len(t, apiErr.Meta, 2)
+	}
+
+	_, err = client.Get(context.Background(), "/unknown", nil)
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "404 page not found", apiErr.Message)
+	}
+}
+
+func TestAPIClientPages(t *testing.T) {
+	server := bookingServer()
+	defer server.Close()
+	client := NewAPIClient(APIClientOpts{BaseURL: server.URL})
+
+	var all []booking
+	var pages []int
+	it := client.Pages(context.Background(), "/bookings?status=paid", 2)
+	var page []booking
+	for it.Next(&page) {
+		all = append(all, page...)
+		pages = append(pages, it.Page().Page)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []int{1, 2, 3}, pages)
+	assert.Len(t, all, 5)
+	assert.Equal(t, 5, all[4].ID)
+
+	it = client.Pages(context.Background(), "/bookings", 2)
+	assert.False(t, it.Next(&page))
+	assert.Error(t, it.Err())
+}
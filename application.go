@@ -11,16 +11,66 @@ package mimir
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 )
 
+const DefaultGracePeriod = 30 * time.Second
+
 type AppRunner func(context.Context) error
 type ApplicationFunc func(context.Context) (AppRunner, func(), error)
 
+// ApplicationOpts configures ApplicationWithOpts. Reload is called on SIGHUP,
+// ForceExit is called when a second shutdown signal arrives while draining.
+type ApplicationOpts struct {
+	Interrupt   InterruptChannel
+	GracePeriod time.Duration
+	Readiness   *Readiness
+	Logger      Logging
+	Reload      func() error
+	ForceExit   func()
+}
+
+// ErrInterrupted is returned by Application when a signal stopped it.
+type ErrInterrupted struct {
+	Signal os.Signal
+}
+
+func (e ErrInterrupted) Error() string {
+	return fmt.Sprintf("interrupt received (%v), shutting down", e.Signal)
+}
+
 func Application(interrupt InterruptChannel, app ApplicationFunc) (func(), error) {
-	ctx, cancel := context.WithCancel(context.Background())
+	return ApplicationWithOpts(ApplicationOpts{Interrupt: interrupt}, app)
+}
+
+// ApplicationWithOpts runs the application until the runner returns or a signal
+// is received. On signal the readiness is flipped to false, the runner context
+// is cancelled and the runner is given GracePeriod to drain its in-flight work.
+// The servers of the runner drain until the same deadline, their own grace
+// period is only used outside of an Application.
+//
+// The readiness turns ready once the servers of the runner listen, see
+// Readiness.Listening, a runner without server sets it itself.
+func ApplicationWithOpts(opts ApplicationOpts, app ApplicationFunc) (func(), error) {
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = DefaultGracePeriod
+	}
+	if opts.Readiness == nil {
+		opts.Readiness = NewReadiness()
+	}
+	if opts.Logger == nil {
+		opts.Logger = With(Field("application", "mimir"))
+	}
+	if opts.ForceExit == nil {
+		opts.ForceExit = func() { os.Exit(1) }
+	}
+	logger := opts.Logger
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), CtxReadiness, opts.Readiness))
 	defer cancel()
 
-	errChan := make(chan error)
+	errChan := make(chan error, 1)
 
 	runner, cleanup, err := app(ctx)
 	if err != nil {
@@ -31,11 +81,65 @@ func Application(interrupt InterruptChannel, app ApplicationFunc) (func(), error
 		errChan <- runner(ctx)
 	}()
 
-	select {
-	case <-interrupt:
-		cancel()
-		return cleanup, fmt.Errorf("interrupt received, shutting down")
-	case err := <-errChan:
-		return cleanup, err
+	var sig os.Signal
+	for sig == nil {
+		select {
+		case s := <-opts.Interrupt:
+			if !handleSignal(opts, s) {
+				sig = s
+			}
+		case err := <-errChan:
+			opts.Readiness.drain(time.Time{})
+			return cleanup, err
+		}
+	}
+
+	deadline := time.Now().Add(opts.GracePeriod)
+	opts.Readiness.drain(deadline)
+	logger.Infof("Received %v, draining for at most %s", sig, opts.GracePeriod)
+	cancel()
+
+	grace := time.NewTimer(time.Until(deadline))
+	defer grace.Stop()
+	for {
+		select {
+		case err := <-errChan:
+			if err != nil {
+				logger.Debugf("Runner stopped: %v", err)
+			}
+			return cleanup, ErrInterrupted{Signal: sig}
+		case <-grace.C:
+			logger.Warnf("Grace period of %s exceeded, forcing shutdown", opts.GracePeriod)
+			return cleanup, ErrInterrupted{Signal: sig}
+		case s := <-opts.Interrupt:
+			if handleSignal(opts, s) {
+				continue
+			}
+			logger.Warnf("Received %v while draining, forcing exit", s)
+			opts.ForceExit()
+			return cleanup, ErrInterrupted{Signal: s}
+		}
+	}
+}
+
+// handleSignal serves the reload and dump signals, it returns false
+// for the signals that shut the application down.
+func handleSignal(opts ApplicationOpts, sig os.Signal) bool {
+	logger := opts.Logger
+	switch {
+	case isReloadSignal(sig):
+		if opts.Reload == nil {
+			logger.Warnf("Received %v, no reload registered", sig)
+			return true
+		}
+		logger.Infof("Received %v, reloading", sig)
+		if err := opts.Reload(); err != nil {
+			logger.Errorf("Reload failed: %v", err)
+		}
+		return true
+	case isDumpSignal(sig):
+		dumpGoroutines(logger)
+		return true
 	}
+	return false
 }
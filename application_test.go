@@ -10,12 +10,16 @@ package mimir
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suryakencana007/mimir/ruuto"
 )
 
 func TestApplication(t *testing.T) {
@@ -69,3 +73,105 @@ func TestApplicationFatalError(t *testing.T) {
 	assert.True(t, cleanupCalled)
 	assert.True(t, runnerInvoke)
 }
+
+func TestApplicationInterrupted(t *testing.T) {
+	interrupt := make(chan os.Signal, 2)
+	readiness := NewReadiness()
+
+	drained := make(chan struct{})
+	cleanup, err := ApplicationWithOpts(ApplicationOpts{
+		Interrupt:   interrupt,
+		GracePeriod: time.Second,
+		Readiness:   readiness,
+	}, func(c context.Context) (AppRunner, func(), error) {
+		assert.Equal(t, readiness, ReadinessFrom(c))
+		return func(ctx context.Context) error {
+			interrupt <- os.Interrupt
+			<-ctx.Done()
+			time.Sleep(20 * time.Millisecond) // in-flight work
+			close(drained)
+			return fmt.Errorf("server interrupted through context")
+		}, func() {}, nil
+	})
+
+	var interrupted ErrInterrupted
+	assert.True(t, errors.As(err, &interrupted))
+	assert.Equal(t, os.Interrupt, interrupted.Signal)
+	assert.NotNil(t, cleanup)
+	assert.False(t, readiness.IsReady())
+	select {
+	case <-drained:
+	default:
+		assert.Fail(t, "application returned before the runner drained")
+	}
+}
+
+func TestApplicationGracePeriodExceeded(t *testing.T) {
+	interrupt := make(chan os.Signal, 2)
+	interrupt <- os.Interrupt
+
+	start := time.Now()
+	_, err := ApplicationWithOpts(ApplicationOpts{
+		Interrupt:   interrupt,
+		GracePeriod: 20 * time.Millisecond,
+	}, func(c context.Context) (AppRunner, func(), error) {
+		return func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}, func() {}, nil
+	})
+
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestApplicationDrainsServersWithinGracePeriod(t *testing.T) {
+	port, err := findOpenPort()
+	require.NoError(t, err)
+	interrupt := make(chan os.Signal, 2)
+	inflight := make(chan struct{})
+	router := ruuto.NewChiRouter()
+	router.GET("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(inflight)
+		time.Sleep(5 * time.Second)
+	})
+
+	stopped := make(chan struct{})
+	start := time.Now()
+	_, err = ApplicationWithOpts(ApplicationOpts{
+		Interrupt:   interrupt,
+		GracePeriod: 100 * time.Millisecond,
+	}, func(c context.Context) (AppRunner, func(), error) {
+		// the server keeps its default grace period
+		run, _ := ListenAndServe(ServeOpts{
+			Logger: With(Field("testing", "TestApplicationDrainsServersWithinGracePeriod")),
+			Port:   WebPort(port),
+			Router: router,
+		})
+		return func(ctx context.Context) error {
+			defer close(stopped)
+			go func() {
+				if waitForPort(port) == nil {
+					resp, err := http.Get(fmt.Sprintf("http://localhost:%d/slow", port))
+					if err == nil {
+						_ = resp.Body.Close()
+					}
+				}
+			}()
+			go func() {
+				<-inflight
+				interrupt <- os.Interrupt
+			}()
+			return run(ctx)
+		}, func() {}, nil
+	})
+
+	var interrupted ErrInterrupted
+	assert.True(t, errors.As(err, &interrupted))
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the server drained past the grace period of the application")
+	}
+	assert.True(t, time.Since(start) < 2*time.Second)
+}
@@ -0,0 +1,74 @@
+/*  auth.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 19:20
+ */
+
+package mimir
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type ctxKeyClaims struct {
+	Name string
+}
+
+func (r *ctxKeyClaims) String() string {
+	return "context value " + r.Name
+}
+
+var CtxClaims = ctxKeyClaims{Name: "context claims"}
+
+var ErrTokenMissing = errors.New("bearer token is missing")
+
+// Authenticate verifies the bearer token of the requests and puts its claims
+// into the request context, it replies with the unauthorized envelope when
+// the token is missing or invalid.
+//
+//	router.Use(mimir.Authenticate(mimir.NewJWT(opts)))
+func Authenticate(verifier TokenVerifier) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := bearerToken(r)
+			if token == "" {
+				unauthorized(w, r, ErrTokenMissing)
+				return
+			}
+			claims, err := verifier.Verify(token)
+			if err != nil {
+				unauthorized(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// WithClaims returns a copy of ctx carrying claims.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, CtxClaims, claims)
+}
+
+// ClaimsFrom returns the claims of an authenticated request, nil otherwise.
+func ClaimsFrom(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(CtxClaims).(*Claims)
+	return claims
+}
+
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(auth[7:])
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	For(r.Context()).Infof("authentication failed %s %s: %v", r.Method, r.URL.Path, err)
+	Response(r).APIStatusUnauthorized(w, r, err).WriteJSON()
+}
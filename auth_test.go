@@ -0,0 +1,79 @@
+/*  auth_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 20:00
+ */
+
+package mimir
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suryakencana007/mimir/ruuto"
+)
+
+func TestAuthenticate(t *testing.T) {
+	clock := newFakeClock()
+	j := NewJWT(JWTOpts{
+		SigningKey: &JWTKey{Algorithm: HS256, Key: []byte("secret")},
+		Claims:     ClaimsOpts{Clock: clock},
+	})
+
+	router := ruuto.NewChiRouter()
+	router.Use(Authenticate(j))
+	router.GET("/me", func(w http.ResponseWriter, r *http.Request) {
+		resp := Response(r)
+		resp.Body(ClaimsFrom(r.Context()).Subject)
+		resp.APIStatusSuccess(w, r).WriteJSON()
+	})
+
+	token, err := j.Issue(Claims{Subject: "42", ExpiresAt: clock.Now().Add(time.Minute)})
+	require.NoError(t, err)
+
+	serve := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("Bearer " + token)
+	require.Equal(t, http.StatusOK, w.Code)
+	var ok struct {
+		Data string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ok))
+	assert.Equal(t, "42", ok.Data)
+
+	for name, auth := range map[string]string{
+		"missing":   "",
+		"basic":     "Basic Zm9vOmJhcg==",
+		"malformed": "Bearer nope",
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := serve(auth)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+			var env struct {
+				Meta []Meta `json:"meta"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+			require.Len(t, env.Meta, 1)
+			assert.Equal(t, "401", env.Meta[0].Code)
+		})
+	}
+
+	clock.Advance(time.Hour)
+	w = serve("Bearer " + token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), ErrTokenExpired.Error())
+}
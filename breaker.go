@@ -9,54 +9,512 @@
 package mimir
 
 import (
-	"github.com/afex/hystrix-go/hystrix"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
 )
 
+const (
+	DefaultBreakerTimeout         = time.Second
+	DefaultMaxConcurrent          = 10
+	DefaultErrorPercentThreshold  = 50
+	DefaultRequestVolumeThreshold = 20
+	DefaultSleepWindow            = 5 * time.Second
+	DefaultRollingWindow          = 10 * time.Second
+	DefaultRollingBuckets         = 10
+	DefaultLatencySamples         = 1024
+)
+
+var (
+	ErrCircuitOpen    = errors.New("circuit breaker is open")
+	ErrMaxConcurrency = errors.New("circuit breaker max concurrency reached")
+	ErrTimeout        = errors.New("circuit breaker timeout")
+)
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState int32
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Clock tells the time to the circuit breaker, tests replace it
+// to move through the sleep and rolling windows.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the wall clock.
+var SystemClock Clock = systemClock{}
+
+// BreakerOpts configures a CircuitBreaker.
+//
+// The breaker trips open when ErrorPercentThreshold of the requests in the
+// RollingWindow failed once RequestVolumeThreshold requests were seen, or
+// after ConsecutiveFailures failures in a row when it is set. After the
+// SleepWindow it lets HalfOpenRequests probes through, the breaker closes
+// when they all succeed and opens again on the first failure.
+type BreakerOpts struct {
+	Name                   string
+	Timeout                time.Duration
+	MaxConcurrent          int
+	ErrorPercentThreshold  int
+	RequestVolumeThreshold int
+	ConsecutiveFailures    int
+	RollingWindow          time.Duration
+	RollingBuckets         int
+	SleepWindow            time.Duration
+	HalfOpenRequests       int
+	// Fallback receives the error of a failed or rejected call,
+	// its result is returned by Execute.
+	Fallback func(error) error
+	Clock    Clock
+	Logger   Logging
+	// noTimeout leaves the calls bounded by their context only.
+	noTimeout bool
+	// noMaxConcurrent admits any number of calls when MaxConcurrent is unset.
+	noMaxConcurrent bool
+}
+
+// StateChangeFunc is called after a CircuitBreaker moved from one state to another.
+type StateChangeFunc func(name string, from, to BreakerState)
+
+// BreakerStats is a snapshot of the rolling window of a CircuitBreaker.
+// Requests counts the executed calls, the rejected and short-circuited
+// calls never reached the dependency.
+type BreakerStats struct {
+	Name          string         `json:"name"`
+	State         string         `json:"state"`
+	Requests      int            `json:"requests"`
+	Failures      int            `json:"failures"`
+	Timeouts      int            `json:"timeouts"`
+	Rejections    int            `json:"rejections"`
+	ShortCircuits int            `json:"short_circuits"`
+	ErrorPercent  int            `json:"error_percent"`
+	Latency       BreakerLatency `json:"latency"`
+}
+
+// BreakerLatency holds the latency percentiles of the executed calls.
+type BreakerLatency struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+type breakerBucket struct {
+	id            int64
+	requests      int
+	failures      int
+	timeouts      int
+	rejections    int
+	shortCircuits int
+}
+
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+type stateChange struct {
+	from, to BreakerState
+}
+
+// CircuitBreaker guards a dependency, failing fast while it is unhealthy.
 type CircuitBreaker struct {
-	name          string
-	maxConcurrent int
-	timeout       int
+	opts   BreakerOpts
+	logger Logging
 
-	fallbackFunc fallbackFunc
+	mu          sync.Mutex
+	state       BreakerState
+	openedAt    time.Time
+	consecutive int
+	running     int
+	probes      int
+	probeOK     int
+	buckets     []breakerBucket
+	latencies   []latencySample
+	latencyNext int
+	hooks       []StateChangeFunc
+	changes     []stateChange
 }
 
-type fallbackFunc func(error) error
+func NewCircuitBreaker(opts BreakerOpts) *CircuitBreaker {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultBreakerTimeout
+	}
+	if opts.MaxConcurrent <= 0 && !opts.noMaxConcurrent {
+		opts.MaxConcurrent = DefaultMaxConcurrent
+	}
+	if opts.ErrorPercentThreshold <= 0 {
+		opts.ErrorPercentThreshold = DefaultErrorPercentThreshold
+	}
+	if opts.RequestVolumeThreshold <= 0 {
+		opts.RequestVolumeThreshold = DefaultRequestVolumeThreshold
+	}
+	if opts.RollingWindow <= 0 {
+		opts.RollingWindow = DefaultRollingWindow
+	}
+	if opts.RollingBuckets <= 0 {
+		opts.RollingBuckets = DefaultRollingBuckets
+	}
+	if opts.SleepWindow <= 0 {
+		opts.SleepWindow = DefaultSleepWindow
+	}
+	if opts.HalfOpenRequests <= 0 {
+		opts.HalfOpenRequests = 1
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+	if opts.Logger == nil {
+		opts.Logger = With(Field("breaker", opts.Name))
+	}
+	return &CircuitBreaker{
+		opts:      opts,
+		logger:    opts.Logger,
+		buckets:   make([]breakerBucket, opts.RollingBuckets),
+		latencies: make([]latencySample, 0, DefaultLatencySamples),
+	}
+}
 
-// SetCommandBreaker the circuit breaker
+// NewBreaker creates a circuit breaker with a timeout in milliseconds,
+// an optional func(error) error is used as fallback.
+// The breaker is a passthrough when the name is empty.
 func NewBreaker(name string, timeout, maxConcurrent int, args ...interface{}) *CircuitBreaker {
-	cb := &CircuitBreaker{
-		name:          name,
-		maxConcurrent: maxConcurrent,
-		timeout:       timeout,
+	opts := BreakerOpts{
+		Name:                  name,
+		Timeout:               time.Duration(timeout) * time.Millisecond,
+		MaxConcurrent:         maxConcurrent,
+		ErrorPercentThreshold: 25,
 	}
 	if len(args) == 1 {
-		switch args[0].(type) {
-		case fallbackFunc:
-			cb.fallbackFunc = args[0].(fallbackFunc)
+		if fallback, ok := args[0].(func(error) error); ok {
+			opts.Fallback = fallback
 		}
 	}
+	return NewCircuitBreaker(opts)
+}
 
-	hystrix.ConfigureCommand(cb.name, hystrix.CommandConfig{
-		MaxConcurrentRequests: cb.maxConcurrent,
-		Timeout:               cb.timeout,
-		ErrorPercentThreshold: 25,
-	})
+func (cb *CircuitBreaker) Name() string {
+	return cb.opts.Name
+}
+
+// OnStateChange registers a hook called after every state transition,
+// the hooks run on the goroutine that caused the transition.
+func (cb *CircuitBreaker) OnStateChange(fn StateChangeFunc) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.hooks = append(cb.hooks, fn)
+}
+
+// State returns the current state, an open breaker past its sleep
+// window reports half-open.
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.unlock()
+	cb.advance(cb.opts.Clock.Now())
+	return cb.state
+}
 
-	return cb
+// Stats returns a snapshot of the rolling window.
+func (cb *CircuitBreaker) Stats() BreakerStats {
+	cb.mu.Lock()
+	defer cb.unlock()
+	now := cb.opts.Clock.Now()
+	cb.advance(now)
+
+	stats := BreakerStats{Name: cb.opts.Name, State: cb.state.String()}
+	current := now.UnixNano() / cb.bucketWidth()
+	for _, b := range cb.buckets {
+		if b.id > current-int64(len(cb.buckets)) && b.id <= current {
+			stats.Requests += b.requests
+			stats.Failures += b.failures
+			stats.Timeouts += b.timeouts
+			stats.Rejections += b.rejections
+			stats.ShortCircuits += b.shortCircuits
+		}
+	}
+	if stats.Requests > 0 {
+		stats.ErrorPercent = (stats.Failures + stats.Timeouts) * 100 / stats.Requests
+	}
+
+	durations := make([]time.Duration, 0, len(cb.latencies))
+	for _, sample := range cb.latencies {
+		if now.Sub(sample.at) < cb.opts.RollingWindow {
+			durations = append(durations, sample.duration)
+		}
+	}
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		percentile := func(p int) time.Duration {
+			return durations[(len(durations)-1)*p/100]
+		}
+		stats.Latency = BreakerLatency{
+			P50: percentile(50),
+			P90: percentile(90),
+			P99: percentile(99),
+			Max: durations[len(durations)-1],
+		}
+	}
+	return stats
+}
+
+// unlock releases the breaker and runs the hooks of the transitions
+// made while it was held.
+func (cb *CircuitBreaker) unlock() {
+	changes, hooks := cb.changes, cb.hooks
+	cb.changes = nil
+	cb.mu.Unlock()
+	for _, change := range changes {
+		for _, hook := range hooks {
+			hook(cb.opts.Name, change.from, change.to)
+		}
+	}
 }
 
 // callBreaker command circuit breaker
-func (cb *CircuitBreaker) Execute(fn func() error) (err error) {
-	if cb.name == "" {
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	return cb.ExecuteContext(context.Background(), func(context.Context) error {
 		return fn()
+	})
+}
+
+// ExecuteContext runs fn through the breaker. The context given to fn is
+// cancelled on the breaker timeout, which returns ErrTimeout. When ctx is
+// done first its error is returned, without counting against the breaker
+// nor calling the fallback.
+func (cb *CircuitBreaker) ExecuteContext(ctx context.Context, fn func(context.Context) error) error {
+	if cb.opts.Name == "" {
+		return fn(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	err = hystrix.Do(cb.name, func() error {
-		return fn()
-	}, nil)
+	probe, err := cb.allow()
+	if err != nil {
+		return cb.fallback(err)
+	}
+
+	start := cb.opts.Clock.Now()
+	err = cb.run(ctx, fn)
+	if ctx.Err() != nil && err == ctx.Err() {
+		cb.release(probe)
+		return err
+	}
+	cb.record(probe, start, err)
+	if err != nil {
+		cb.logger.Errorf("Circuit breaker %s call failed: %v", cb.opts.Name, err)
+		return cb.fallback(err)
+	}
+	return nil
+}
+
+func (cb *CircuitBreaker) run(ctx context.Context, fn func(context.Context) error) error {
+	callCtx, cancel := context.WithCancel(ctx)
+	if !cb.opts.noTimeout {
+		callCtx, cancel = context.WithTimeout(ctx, cb.opts.Timeout)
+	}
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(callCtx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && ctx.Err() == nil && callCtx.Err() == context.DeadlineExceeded {
+			return ErrTimeout
+		}
+		return err
+	case <-callCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return ErrTimeout
+	}
+}
+
+func (cb *CircuitBreaker) fallback(err error) error {
+	if cb.opts.Fallback == nil {
+		return err
+	}
+	return cb.opts.Fallback(err)
+}
+
+// allow admits a call, it reports whether the call is a half-open probe.
+func (cb *CircuitBreaker) allow() (bool, error) {
+	cb.mu.Lock()
+	defer cb.unlock()
+	now := cb.opts.Clock.Now()
+	cb.advance(now)
+
+	probe := false
+	switch cb.state {
+	case StateOpen:
+		cb.bucket(now).shortCircuits++
+		return false, ErrCircuitOpen
+	case StateHalfOpen:
+		if cb.probes >= cb.opts.HalfOpenRequests {
+			cb.bucket(now).shortCircuits++
+			return false, ErrCircuitOpen
+		}
+		probe = true
+	}
+	if cb.opts.MaxConcurrent > 0 && cb.running >= cb.opts.MaxConcurrent {
+		cb.bucket(now).rejections++
+		return false, ErrMaxConcurrency
+	}
+	if probe {
+		cb.probes++
+	}
+	cb.running++
+	return probe, nil
+}
+
+// release gives back the admission of a call abandoned by its caller.
+func (cb *CircuitBreaker) release(probe bool) {
+	cb.mu.Lock()
+	defer cb.unlock()
+	cb.running--
+	if probe && cb.state == StateHalfOpen && cb.probes > 0 {
+		cb.probes--
+	}
+}
+
+func (cb *CircuitBreaker) record(probe bool, start time.Time, err error) {
+	cb.mu.Lock()
+	defer cb.unlock()
+	now := cb.opts.Clock.Now()
+	cb.running--
 
+	sample := latencySample{at: now, duration: now.Sub(start)}
+	if len(cb.latencies) < cap(cb.latencies) {
+		cb.latencies = append(cb.latencies, sample)
+	} else {
+		cb.latencies[cb.latencyNext] = sample
+	}
+	cb.latencyNext = (cb.latencyNext + 1) % cap(cb.latencies)
+
+	bucket := cb.bucket(now)
+	bucket.requests++
+	switch {
+	case errors.Is(err, ErrTimeout):
+		bucket.timeouts++
+	case err != nil:
+		bucket.failures++
+	}
 	if err != nil {
-		Errorf("Call Breake Hystrix Do %v", err)
+		cb.consecutive++
+	} else {
+		cb.consecutive = 0
+	}
+
+	if probe {
+		if cb.state != StateHalfOpen {
+			return
+		}
+		if err != nil {
+			cb.transition(StateOpen, now)
+			return
+		}
+		cb.probeOK++
+		if cb.probeOK >= cb.opts.HalfOpenRequests {
+			cb.transition(StateClosed, now)
+		}
+		return
+	}
+
+	if cb.state == StateClosed && err != nil && cb.tripped(now) {
+		cb.transition(StateOpen, now)
+	}
+}
+
+// tripped evaluates the trip policies against the rolling window.
+func (cb *CircuitBreaker) tripped(now time.Time) bool {
+	if cb.opts.ConsecutiveFailures > 0 && cb.consecutive >= cb.opts.ConsecutiveFailures {
+		return true
+	}
+	requests, failures := cb.window(now)
+	if requests < cb.opts.RequestVolumeThreshold {
+		return false
+	}
+	return failures*100 >= cb.opts.ErrorPercentThreshold*requests
+}
+
+// advance moves an open breaker to half-open once the sleep window elapsed.
+func (cb *CircuitBreaker) advance(now time.Time) {
+	if cb.state == StateOpen && now.Sub(cb.openedAt) >= cb.opts.SleepWindow {
+		cb.transition(StateHalfOpen, now)
+	}
+}
+
+func (cb *CircuitBreaker) transition(state BreakerState, now time.Time) {
+	if cb.state == state {
+		return
+	}
+	cb.logger.Infof("Circuit breaker %s %s -> %s", cb.opts.Name, cb.state, state)
+	cb.changes = append(cb.changes, stateChange{from: cb.state, to: state})
+	cb.state = state
+	cb.probes, cb.probeOK = 0, 0
+	switch state {
+	case StateOpen:
+		cb.openedAt = now
+	case StateClosed:
+		cb.consecutive = 0
+		for i := range cb.buckets {
+			cb.buckets[i] = breakerBucket{}
+		}
+	}
+}
+
+func (cb *CircuitBreaker) bucketWidth() int64 {
+	width := int64(cb.opts.RollingWindow) / int64(len(cb.buckets))
+	if width <= 0 {
+		width = 1
+	}
+	return width
+}
+
+func (cb *CircuitBreaker) bucket(now time.Time) *breakerBucket {
+	id := now.UnixNano() / cb.bucketWidth()
+	b := &cb.buckets[int(id%int64(len(cb.buckets)))]
+	if b.id != id {
+		*b = breakerBucket{id: id}
+	}
+	return b
+}
+
+// window sums the buckets of the rolling window.
+func (cb *CircuitBreaker) window(now time.Time) (requests, failures int) {
+	current := now.UnixNano() / cb.bucketWidth()
+	for _, b := range cb.buckets {
+		if b.id > current-int64(len(cb.buckets)) && b.id <= current {
+			requests += b.requests
+			failures += b.failures + b.timeouts
+		}
 	}
-	return err
+	return requests, failures
 }
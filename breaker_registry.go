@@ -0,0 +1,78 @@
+/*  breaker_registry.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 12:10
+ */
+
+package mimir
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/suryakencana007/mimir/ruuto"
+)
+
+// BreakerRegistry holds the circuit breakers of an application by name.
+type BreakerRegistry struct {
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+}
+
+func NewBreakerRegistry() *BreakerRegistry {
+	return &BreakerRegistry{breakers: make(map[string]*CircuitBreaker)}
+}
+
+// Register adds a circuit breaker, the name must be unique.
+func (r *BreakerRegistry) Register(cb *CircuitBreaker) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cb.Name() == "" {
+		return fmt.Errorf("circuit breaker name is required")
+	}
+	if _, ok := r.breakers[cb.Name()]; ok {
+		return fmt.Errorf("circuit breaker %q already registered", cb.Name())
+	}
+	r.breakers[cb.Name()] = cb
+	return nil
+}
+
+// Breaker returns the circuit breaker registered under name, nil when unknown.
+func (r *BreakerRegistry) Breaker(name string) *CircuitBreaker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.breakers[name]
+}
+
+// Stats returns the snapshot of every registered breaker sorted by name.
+func (r *BreakerRegistry) Stats() []BreakerStats {
+	r.mu.RLock()
+	breakers := make([]*CircuitBreaker, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		breakers = append(breakers, cb)
+	}
+	r.mu.RUnlock()
+
+	stats := make([]BreakerStats, 0, len(breakers))
+	for _, cb := range breakers {
+		stats = append(stats, cb.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// BreakerHandler serves the state of every registered breaker.
+func BreakerHandler(registry *BreakerRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := Response(r)
+		resp.Body(registry.Stats())
+		resp.APIStatusSuccess(w, r).WriteJSON()
+	}
+}
+
+// MountBreakers serves the breaker states on GET path of the router.
+func MountBreakers(router ruuto.Router, path string, registry *BreakerRegistry) {
+	router.GET(path, BreakerHandler(registry))
+}
@@ -0,0 +1,50 @@
+/*  breaker_registry_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 12:25
+ */
+
+package mimir
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/suryakencana007/mimir/ruuto"
+)
+
+func TestBreakerRegistryHandler(t *testing.T) {
+	registry := NewBreakerRegistry()
+	payment := NewCircuitBreaker(BreakerOpts{Name: "payment", ConsecutiveFailures: 1})
+	assert.NoError(t, registry.Register(NewCircuitBreaker(BreakerOpts{Name: "inventory"})))
+	assert.NoError(t, registry.Register(payment))
+	assert.Error(t, registry.Register(NewCircuitBreaker(BreakerOpts{Name: "payment"})))
+	assert.Error(t, registry.Register(NewBreaker("", 100, 10)))
+	assert.Equal(t, payment, registry.Breaker("payment"))
+
+	assert.Error(t, payment.Execute(failing))
+
+	router := ruuto.NewChiRouter()
+	MountBreakers(router, "/breakers", registry)
+
+	r, err := http.NewRequest(http.MethodGet, "/breakers", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, StatusSuccess, w.Code)
+
+	var body struct {
+		Data []BreakerStats `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	if assert.Len(t, body.Data, 2) {
+		assert.Equal(t, "inventory", body.Data[0].Name)
+		assert.Equal(t, "closed", body.Data[0].State)
+		assert.Equal(t, "payment", body.Data[1].Name)
+		assert.Equal(t, "open", body.Data[1].State)
+		assert.Equal(t, 1, body.Data[1].Failures)
+	}
+}
@@ -7,3 +7,272 @@
  */
 
 package mimir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+var errBackend = errors.New("backend unavailable")
+
+func failing() error    { return errBackend }
+func succeeding() error { return nil }
+
+func TestBreakerErrorPercentage(t *testing.T) {
+	clock := newFakeClock()
+	cb := NewCircuitBreaker(BreakerOpts{
+		Name:                   "percentage",
+		ErrorPercentThreshold:  50,
+		RequestVolumeThreshold: 4,
+		SleepWindow:            time.Second,
+		Clock:                  clock,
+	})
+
+	assert.NoError(t, cb.Execute(succeeding))
+	assert.NoError(t, cb.Execute(succeeding))
+	assert.Equal(t, errBackend, cb.Execute(failing))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, errBackend, cb.Execute(failing))
+	assert.Equal(t, StateOpen, cb.State())
+
+	called := false
+	err := cb.Execute(func() error {
+		called = true
+		return nil
+	})
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.False(t, called)
+}
+
+func TestBreakerRollingWindow(t *testing.T) {
+	clock := newFakeClock()
+	cb := NewCircuitBreaker(BreakerOpts{
+		Name:                   "window",
+		RequestVolumeThreshold: 2,
+		RollingWindow:          time.Second,
+		RollingBuckets:         10,
+		Clock:                  clock,
+	})
+
+	assert.Error(t, cb.Execute(failing))
+	clock.Advance(2 * time.Second)
+	assert.Error(t, cb.Execute(failing))
+	assert.Equal(t, StateClosed, cb.State(), "failures outside the window are forgotten")
+	assert.Error(t, cb.Execute(failing))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestBreakerConsecutiveFailures(t *testing.T) {
+	clock := newFakeClock()
+	cb := NewCircuitBreaker(BreakerOpts{
+		Name:                   "consecutive",
+		ConsecutiveFailures:    3,
+		RequestVolumeThreshold: 100,
+		Clock:                  clock,
+	})
+
+	assert.Error(t, cb.Execute(failing))
+	assert.Error(t, cb.Execute(failing))
+	assert.NoError(t, cb.Execute(succeeding))
+	assert.Error(t, cb.Execute(failing))
+	assert.Error(t, cb.Execute(failing))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Error(t, cb.Execute(failing))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	clock := newFakeClock()
+	cb := NewCircuitBreaker(BreakerOpts{
+		Name:                "half-open",
+		ConsecutiveFailures: 1,
+		SleepWindow:         time.Second,
+		HalfOpenRequests:    2,
+		Clock:               clock,
+	})
+
+	assert.Error(t, cb.Execute(failing))
+	assert.Equal(t, StateOpen, cb.State())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Error(t, cb.Execute(failing))
+	assert.Equal(t, StateOpen, cb.State(), "a failed probe opens the breaker again")
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, ErrCircuitOpen, cb.Execute(succeeding))
+
+	clock.Advance(500 * time.Millisecond)
+	assert.NoError(t, cb.Execute(succeeding))
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.NoError(t, cb.Execute(succeeding))
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestBreakerMaxConcurrency(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerOpts{Name: "concurrency", MaxConcurrent: 1})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- cb.Execute(func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+
+	<-started
+	assert.Equal(t, ErrMaxConcurrency, cb.Execute(succeeding))
+	close(release)
+	assert.NoError(t, <-done)
+}
+
+func TestBreakerTimeoutAndFallback(t *testing.T) {
+	var fallbackErr error
+	cb := NewBreaker("timeout", 10, 1, func(err error) error {
+		fallbackErr = err
+		return nil
+	})
+
+	assert.NoError(t, cb.Execute(func() error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}))
+	assert.Equal(t, ErrTimeout, fallbackErr)
+}
+
+func TestBreakerPassthrough(t *testing.T) {
+	cb := NewBreaker("", 100, 10)
+	assert.Equal(t, errBackend, cb.Execute(failing))
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestBreakerStateChangeHooks(t *testing.T) {
+	clock := newFakeClock()
+	cb := NewCircuitBreaker(BreakerOpts{
+		Name:                "hooks",
+		ConsecutiveFailures: 1,
+		SleepWindow:         time.Second,
+		Clock:               clock,
+	})
+
+	var changes []string
+	cb.OnStateChange(func(name string, from, to BreakerState) {
+		// hooks may call back into the breaker
+		assert.Equal(t, to, cb.State())
+		changes = append(changes, name+": "+from.String()+" -> "+to.String())
+	})
+
+	assert.Error(t, cb.Execute(failing))
+	clock.Advance(time.Second)
+	assert.NoError(t, cb.Execute(succeeding))
+	assert.Equal(t, []string{
+		"hooks: closed -> open",
+		"hooks: open -> half-open",
+		"hooks: half-open -> closed",
+	}, changes)
+}
+
+func TestBreakerStats(t *testing.T) {
+	clock := newFakeClock()
+	cb := NewCircuitBreaker(BreakerOpts{
+		Name:                "stats",
+		ConsecutiveFailures: 3,
+		Clock:               clock,
+	})
+
+	for i := 1; i <= 4; i++ {
+		latency := time.Duration(i) * 10 * time.Millisecond
+		assert.NoError(t, cb.Execute(func() error {
+			clock.Advance(latency)
+			return nil
+		}))
+	}
+	assert.Error(t, cb.Execute(failing))
+	assert.Error(t, cb.Execute(failing))
+	assert.Error(t, cb.Execute(failing))
+	assert.Equal(t, ErrCircuitOpen, cb.Execute(succeeding))
+
+	stats := cb.Stats()
+	assert.Equal(t, "stats", stats.Name)
+	assert.Equal(t, "open", stats.State)
+	assert.Equal(t, 7, stats.Requests)
+	assert.Equal(t, 3, stats.Failures)
+	assert.Equal(t, 1, stats.ShortCircuits)
+	assert.Equal(t, 42, stats.ErrorPercent)
+	assert.Equal(t, 40*time.Millisecond, stats.Latency.Max)
+	assert.Equal(t, 10*time.Millisecond, stats.Latency.P50)
+}
+
+func TestBreakerExecuteContext(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerOpts{
+		Name:                "context",
+		Timeout:             20 * time.Millisecond,
+		ConsecutiveFailures: 2,
+	})
+
+	err := cb.ExecuteContext(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.Equal(t, 1, cb.Stats().Timeouts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+	}()
+	err = cb.ExecuteContext(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.Equal(t, context.Canceled, err)
+
+	called := false
+	err = cb.ExecuteContext(ctx, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, called)
+
+	stats := cb.Stats()
+	assert.Equal(t, 1, stats.Requests, "cancelled calls do not count against the breaker")
+	assert.Equal(t, "closed", stats.State)
+
+	assert.True(t, errors.Is(cb.ExecuteContext(context.Background(), func(ctx context.Context) error {
+		return fmt.Errorf("query: %w", context.DeadlineExceeded)
+	}), context.DeadlineExceeded))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.True(t, errors.Is(cb.Execute(succeeding), ErrCircuitOpen))
+}
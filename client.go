@@ -0,0 +1,396 @@
+/*  client.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 15:20
+ */
+
+package mimir
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	opExt "github.com/opentracing/opentracing-go/ext"
+)
+
+const DefaultClientDialTimeout = 30
+
+type ctxKeyRequestTimeout struct {
+	Name string
+}
+
+func (r *ctxKeyRequestTimeout) String() string {
+	return "context value " + r.Name
+}
+
+var CtxRequestTimeout = ctxKeyRequestTimeout{Name: "context request timeout"}
+
+type (
+	// TransportMiddleware decorates a RoundTripper, teams add their own
+	// through ClientOpts.Middlewares.
+	TransportMiddleware func(http.RoundTripper) http.RoundTripper
+
+	// RoundTripperFunc adapts a function to http.RoundTripper.
+	RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+	// ClientOpts configures NewClient. Every layer is optional, the request
+	// goes through logging, tracing, timeout, retry, the per-host breaker
+	// and then Middlewares before reaching Transport.
+	ClientOpts struct {
+		// DialTimeout in seconds of the DefaultPooledTransport.
+		DialTimeout int
+		Transport   http.RoundTripper
+		Logger      Logging
+		Tracer      opentracing.Tracer
+		// RequestTimeout bounds every request, RequestTimeout of the
+		// context takes precedence.
+		RequestTimeout time.Duration
+		Retry          *Retry
+		// Breaker is the template of the per-host breakers, they are named
+		// after the host and registered on BreakerRegistry when it is set.
+		Breaker         *BreakerOpts
+		BreakerRegistry *BreakerRegistry
+		Middlewares     []TransportMiddleware
+	}
+)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// ChainTransport wraps base with the middlewares, the first one is the outermost.
+func ChainTransport(base http.RoundTripper, middlewares ...TransportMiddleware) http.RoundTripper {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		base = middlewares[i](base)
+	}
+	return base
+}
+
+// NewClient builds an http.Client on a pooled transport with the
+// resilience layers of opts.
+func NewClient(opts ClientOpts) *http.Client {
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DefaultClientDialTimeout
+	}
+	if opts.Transport == nil {
+		opts.Transport = DefaultPooledTransport(opts.DialTimeout)
+	}
+
+	middlewares := make([]TransportMiddleware, 0, 5+len(opts.Middlewares))
+	if opts.Logger != nil {
+		middlewares = append(middlewares, LoggingTransport(opts.Logger))
+	}
+	if opts.Tracer != nil {
+		middlewares = append(middlewares, TracingTransport(opts.Tracer))
+	}
+	middlewares = append(middlewares, TimeoutTransport(opts.RequestTimeout))
+	if opts.Retry != nil {
+		middlewares = append(middlewares, RetryTransport(*opts.Retry))
+	}
+	if opts.Breaker != nil {
+		middlewares = append(middlewares, BreakerTransport(*opts.Breaker, opts.BreakerRegistry))
+	}
+	middlewares = append(middlewares, opts.Middlewares...)
+
+	return &http.Client{
+		Transport: ChainTransport(opts.Transport, middlewares...),
+	}
+}
+
+// RequestTimeout sets the timeout of the outbound requests made with ctx.
+func RequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, CtxRequestTimeout, timeout)
+}
+
+// TimeoutTransport bounds the request, headers and body included, by the
+// RequestTimeout of its context or else by timeout. Zero disables it.
+func TimeoutTransport(timeout time.Duration) TransportMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			d := timeout
+			if v, ok := req.Context().Value(CtxRequestTimeout).(time.Duration); ok {
+				d = v
+			}
+			if d <= 0 {
+				return next.RoundTrip(req)
+			}
+			ctx, cancel := context.WithTimeout(req.Context(), d)
+			resp, err := next.RoundTrip(req.WithContext(ctx))
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		})
+	}
+}
+
+// TracingTransport starts a client span, child of the span of the request
+// context, and injects it into the request headers for TracerServer.
+func TracingTransport(tracer opentracing.Tracer) TransportMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			operation := fmt.Sprintf("HTTP %s %s", req.Method, req.URL.Host)
+			var span opentracing.Span
+			if parent := opentracing.SpanFromContext(req.Context()); parent != nil {
+				span = tracer.StartSpan(operation, opentracing.ChildOf(parent.Context()))
+			} else {
+				span = tracer.StartSpan(operation)
+			}
+			defer span.Finish()
+
+			opExt.SpanKindRPCClient.Set(span)
+			opExt.HTTPMethod.Set(span, req.Method)
+			opExt.HTTPUrl.Set(span, req.URL.String())
+
+			// never alter the headers of the caller
+			req = req.Clone(opentracing.ContextWithSpan(req.Context(), span))
+			if err := tracer.Inject(
+				span.Context(),
+				opentracing.HTTPHeaders,
+				opentracing.HTTPHeadersCarrier(req.Header),
+			); err != nil {
+				For(req.Context()).Warnf("tracing err %s", err)
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				opExt.Error.Set(span, true)
+				span.LogKV("event", "error", "message", err.Error())
+				return nil, err
+			}
+			opExt.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+			if resp.StatusCode >= http.StatusInternalServerError {
+				opExt.Error.Set(span, true)
+			}
+			return resp, nil
+		})
+	}
+}
+
+// LoggingTransport logs every request and its response through logger.
+func LoggingTransport(logger Logging) TransportMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			logger.Debug("Started outbound request",
+				logger.Field("method", req.Method),
+				logger.Field("url", req.URL.String()),
+			)
+			resp, err := next.RoundTrip(req)
+			duration := time.Since(start)
+			if err != nil {
+				logger.Errorf("Outbound request %s %s failed after %s: %v", req.Method, req.URL, duration, err)
+				return nil, err
+			}
+			logger.Info("Completed outbound request",
+				logger.Field("code", resp.StatusCode),
+				logger.Field("duration", int(duration/time.Millisecond)),
+				logger.Field("duration-fmt", duration.String()),
+				logger.Field("method", req.Method),
+				logger.Field("url", req.URL.String()),
+			)
+			return resp, nil
+		})
+	}
+}
+
+// RetryTransport retries the idempotent requests, the ones with an
+// Idempotency-Key header included, on transport errors and on 502, 503
+// and 504 responses. The last response is returned when the retries are
+// exhausted on a status. The Breaker of the policy is not used, the hosts
+// are guarded by BreakerTransport.
+func RetryTransport(retry Retry) TransportMiddleware {
+	retry.Breaker = nil
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !isIdempotent(req) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+				return next.RoundTrip(req)
+			}
+
+			var last *http.Response
+			attempt := 0
+			err := retry.Do(req.Context(), func(ctx context.Context) error {
+				if last != nil {
+					drainBody(last.Body)
+					last = nil
+				}
+				out := req
+				if attempt > 0 && req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return err
+					}
+					out = req.Clone(req.Context())
+					out.Body = body
+				}
+				attempt++
+
+				resp, err := next.RoundTrip(out)
+				if err != nil {
+					return err
+				}
+				last = resp
+				if isRetryableStatus(resp.StatusCode) {
+					return &statusError{code: resp.StatusCode}
+				}
+				return nil
+			})
+			if _, ok := err.(*statusError); ok && last != nil {
+				return last, nil
+			}
+			if err != nil {
+				if last != nil {
+					drainBody(last.Body)
+				}
+				return nil, err
+			}
+			return last, nil
+		})
+	}
+}
+
+// BreakerTransport guards every host with its own circuit breaker built
+// from opts, 5xx responses count as failures but are still returned.
+//
+// The requests are rejected with the breaker error, ErrCircuitOpen when it
+// is open, opts.Fallback is not used. The breakers have no timeout and
+// admit any number of requests unless opts.Timeout and opts.MaxConcurrent
+// are set, the requests are bounded by TimeoutTransport.
+func BreakerTransport(opts BreakerOpts, registry *BreakerRegistry) TransportMiddleware {
+	var mu sync.Mutex
+	breakers := make(map[string]*CircuitBreaker)
+	breaker := func(host string) *CircuitBreaker {
+		mu.Lock()
+		defer mu.Unlock()
+		if cb, ok := breakers[host]; ok {
+			return cb
+		}
+		o := opts
+		o.Fallback = nil
+		o.noTimeout = opts.Timeout <= 0
+		o.noMaxConcurrent = opts.MaxConcurrent <= 0
+		o.Name = host
+		if opts.Name != "" {
+			o.Name = opts.Name + ":" + host
+		}
+		cb := NewCircuitBreaker(o)
+		if registry != nil {
+			if err := registry.Register(cb); err != nil {
+				if existing := registry.Breaker(o.Name); existing != nil {
+					cb = existing
+				}
+			}
+		}
+		breakers[host] = cb
+		return cb
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// the response body outlives the breaker call,
+			// it is cancelled when the body is closed
+			ctx, cancel := context.WithCancel(req.Context())
+			var (
+				guard     sync.Mutex
+				resp      *http.Response
+				started   bool
+				abandoned bool
+			)
+			err := breaker(req.URL.Host).ExecuteContext(ctx, func(context.Context) error {
+				guard.Lock()
+				if abandoned {
+					guard.Unlock()
+					return ctx.Err()
+				}
+				started = true
+				guard.Unlock()
+				r, err := next.RoundTrip(req.WithContext(ctx))
+				guard.Lock()
+				defer guard.Unlock()
+				if abandoned {
+					// the breaker gave up on the call already
+					if r != nil {
+						drainBody(r.Body)
+					}
+					return err
+				}
+				if err != nil {
+					return err
+				}
+				resp = r
+				if resp.StatusCode >= http.StatusInternalServerError {
+					return &statusError{code: resp.StatusCode}
+				}
+				return nil
+			})
+			guard.Lock()
+			abandoned = true
+			sent := started
+			guard.Unlock()
+			if !sent && req.Body != nil {
+				// the request was rejected, the transport never closes its body
+				_ = req.Body.Close()
+			}
+			if _, ok := err.(*statusError); ok && resp != nil {
+				err = nil
+			}
+			if err != nil {
+				cancel()
+				if resp != nil {
+					drainBody(resp.Body)
+				}
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		})
+	}
+}
+
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.code, http.StatusText(e.code))
+}
+
+// cancelBody releases the request context once the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusBadGateway ||
+		code == http.StatusServiceUnavailable ||
+		code == http.StatusGatewayTimeout
+}
+
+func drainBody(body io.ReadCloser) {
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(body, 4096))
+	_ = body.Close()
+}
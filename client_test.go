@@ -0,0 +1,256 @@
+/*  client_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 15:50
+ */
+
+package mimir
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+)
+
+func noSleep(context.Context, time.Duration) error { return nil }
+
+func TestChainTransport(t *testing.T) {
+	var order []string
+	middleware := func(name string) TransportMiddleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		order = append(order, "base")
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody}, nil
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "http://booking.local/", nil)
+	assert.NoError(t, err)
+	resp, err := ChainTransport(base, middleware("first"), middleware("second")).RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, []string{"first", "second", "base"}, order)
+}
+
+func TestClientRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientOpts{Retry: &Retry{MaxAttempts: 3, Sleep: noSleep}})
+
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("booking"))
+	assert.NoError(t, err)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "booking", string(body), "the body is replayed on retry")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	resp, err = client.Post(server.URL, "text/plain", strings.NewReader("order"))
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "a POST is not retried")
+}
+
+func TestClientBreaker(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	registry := NewBreakerRegistry()
+	client := NewClient(ClientOpts{
+		Breaker:         &BreakerOpts{Name: "upstream", ConsecutiveFailures: 2},
+		BreakerRegistry: registry,
+	})
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	}
+	_, err := client.Get(server.URL)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	stats := registry.Stats()
+	if assert.Len(t, stats, 1) {
+		assert.Equal(t, "upstream:"+strings.TrimPrefix(server.URL, "http://"), stats[0].Name)
+		assert.Equal(t, "open", stats[0].State)
+	}
+}
+
+func TestClientBreakerFallbackAndTimeout(t *testing.T) {
+	var slow int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&slow) == 1 {
+			time.Sleep(DefaultBreakerTimeout + 100*time.Millisecond)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	// the transport never returns the result of the fallback
+	client := NewClient(ClientOpts{Breaker: &BreakerOpts{
+		Name:                "fallback",
+		ConsecutiveFailures: 1,
+		Fallback:            func(error) error { return nil },
+	}})
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	_, err = client.Get(server.URL)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	// the breaker does not cap the requests
+	atomic.StoreInt32(&slow, 1)
+	resp, err = NewClient(ClientOpts{Breaker: &BreakerOpts{Name: "slow"}}).Get(server.URL)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+}
+
+func TestClientBreakerConcurrency(t *testing.T) {
+	const requests = DefaultMaxConcurrent + 5
+	var (
+		wg      sync.WaitGroup
+		running int32
+	)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&running, 1) == requests {
+			close(release)
+		}
+		select {
+		case <-release:
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	// the breakers do not cap the concurrent requests to a host
+	client := NewClient(ClientOpts{Breaker: &BreakerOpts{Name: "concurrent"}})
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	// the body of a rejected request is closed
+	transport := BreakerTransport(BreakerOpts{Name: "capped", ConsecutiveFailures: 1}, nil)(
+		RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}))
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	assert.Error(t, err)
+	body := &closeSpy{Reader: strings.NewReader("payload")}
+	req, err = http.NewRequest(http.MethodPost, server.URL, body)
+	assert.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.True(t, body.closed)
+}
+
+type closeSpy struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeSpy) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(ClientOpts{RequestTimeout: time.Minute})
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	_, err = client.Do(req.WithContext(RequestTimeout(req.Context(), 20*time.Millisecond)))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestClientTracing(t *testing.T) {
+	tracer := mocktracer.New()
+	var traceHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceHeader = r.Header.Get("Mockpfx-Ids-Traceid")
+	}))
+	defer server.Close()
+
+	parent := tracer.StartSpan("booking")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	client := NewClient(ClientOpts{Tracer: tracer})
+	resp, err := client.Do(req.WithContext(ctx))
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	parent.Finish()
+
+	spans := tracer.FinishedSpans()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, spans[0].ParentID)
+		assert.Equal(t, uint16(http.StatusOK), spans[0].Tag("http.status_code"))
+	}
+	assert.NotEmpty(t, traceHeader)
+	assert.Empty(t, req.Header.Get("Mockpfx-Ids-Traceid"), "the caller headers are untouched")
+}
@@ -9,36 +9,113 @@
 package mimir
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+const (
+	DefaultProfileEnv = "APP_PROFILE"
+	ProfileFlag       = "profile"
+)
+
+// Configuration layers, the later one takes precedence:
+//
+//	file     <Filename>            e.g. app.config.yaml
+//	profile  <Filename>.<profile>  e.g. app.config.production.yaml
+//	dotenv   .env
+//	env      ENV_ prefixed environment variables and ConfigFunc bindings
+//	flag     command-line flags set on ConfigOpts.Flags
+//
+// The profile is ConfigOpts.Profile, else the --profile flag, else the
+// ConfigOpts.ProfileEnv environment variable (APP_PROFILE by default).
+const (
+	LayerFile    = "file"
+	LayerProfile = "profile"
+	LayerDotEnv  = "dotenv"
+	LayerEnv     = "env"
+	LayerFlag    = "flag"
+)
+
 type (
 	ConfigConstants interface{}
 	ConfigFunc      func(*viper.Viper) error
 	ConfigOpts      struct {
-		Config   ConfigConstants
-		Filename string
-		Paths    []string
+		Config     ConfigConstants
+		Filename   string
+		Paths      []string
+		Profile    string
+		ProfileEnv string
+		Flags      *pflag.FlagSet
+		// Validate checks the populated configuration after its validate tags,
+		// a reload is rejected when it fails.
+		Validate func(ConfigConstants) error
+		// WatchInterval is the polling interval of WatchConfig.
+		WatchInterval time.Duration
+		Logger        Logging
+		// SecretProviders resolves ${name:reference} values next to the
+		// built-in file and env providers.
+		SecretProviders map[string]SecretProvider
 	}
 )
 
+// configSource is the outcome of reading every configuration layer.
+type configSource struct {
+	viper   *viper.Viper
+	files   []string
+	secrets map[string]bool
+	origins map[string]string
+}
+
 func Config(opts ConfigOpts, configFunc ConfigFunc) error {
-	v := viper.New()
-	// Search the root directory for the configuration file
-	for _, path := range opts.Paths {
-		v.AddConfigPath(path)
+	_, err := loadConfig(opts, configFunc, opts.Config)
+	return err
+}
+
+// DumpConfig writes the effective configuration with the layer every key
+// comes from, resolved secrets are redacted.
+func DumpConfig(out io.Writer, opts ConfigOpts, configFunc ConfigFunc) error {
+	dest := ConfigConstants(&map[string]interface{}{})
+	if t := reflect.TypeOf(opts.Config); t != nil && t.Kind() == reflect.Ptr {
+		dest = reflect.New(t.Elem()).Interface()
 	}
+	source, err := loadConfig(opts, configFunc, dest)
+	if err != nil {
+		return err
+	}
+	return source.dump(out)
+}
 
-	v.SetConfigName(opts.Filename) // Configuration fileName without the .TOML or .YAML extension
+// loadConfig reads the configuration layers and unmarshal them into dest.
+// The source is returned along with an unmarshal or validation error so
+// the rejected values can be reported.
+func loadConfig(opts ConfigOpts, configFunc ConfigFunc, dest ConfigConstants) (*configSource, error) {
+	v := viper.New()
+	source := &configSource{viper: v, origins: make(map[string]string)}
 
-	if err := v.ReadInConfig(); err != nil {
-		return err
+	if err := source.mergeFile(LayerFile, opts.Filename, opts.Paths, ""); err != nil {
+		return nil, err
 	}
 
-	if err := v.Unmarshal(&opts.Config); err != nil {
-		return err
+	if profile := configProfile(opts); profile != "" {
+		err := source.mergeFile(LayerProfile, fmt.Sprintf("%s.%s", opts.Filename, profile), opts.Paths, "")
+		if _, ok := err.(viper.ConfigFileNotFoundError); err != nil && !ok {
+			return source, err
+		}
+	}
+
+	if err := source.mergeFile(LayerDotEnv, ".env", opts.Paths, "env"); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return source, err
+		}
 	}
 
 	v.SetEnvPrefix("env")
@@ -47,28 +124,178 @@ func Config(opts ConfigOpts, configFunc ConfigFunc) error {
 	v.AutomaticEnv()
 
 	if err := configFunc(v); err != nil {
-		return err
+		return source, err
+	}
+	for _, key := range v.AllKeys() {
+		if _, ok := os.LookupEnv(configEnvKey(key)); ok {
+			source.origins[key] = LayerEnv + ":" + configEnvKey(key)
+		}
 	}
 
-	if err := v.MergeInConfig(); err != nil {
-		return err
+	if err := source.bindFlags(opts.Flags, dest); err != nil {
+		return source, err
 	}
 
-	if err := v.UnmarshalExact(&opts.Config); err != nil {
-		return err
+	secrets, err := resolveSecrets(v, secretProviders(opts.SecretProviders))
+	if err != nil {
+		return source, err
 	}
+	source.secrets = secrets
 
-	for _, path := range opts.Paths {
-		v.AddConfigPath(path)
+	if err := v.UnmarshalExact(dest); err != nil {
+		return source, err
 	}
-	v.SetConfigName(".env")
-	v.SetConfigType("env")
 
-	if err := v.MergeInConfig(); err == nil {
-		if err := v.UnmarshalExact(&opts.Config); err != nil {
-			return err
+	if errs := ValidateConfig(dest); len(errs) > 0 {
+		for i := range errs {
+			if secrets[errs[i].Field] {
+				errs[i].Value = Redacted
+				errs[i].Message = fmt.Sprintf("Invalid value %s for config %s", Redacted, errs[i].Field)
+			}
+		}
+		return source, ConfigValidationError(errs)
+	}
+
+	if opts.Validate != nil {
+		if err := opts.Validate(dest); err != nil {
+			return source, err
 		}
 	}
 
+	return source, nil
+}
+
+func configProfile(opts ConfigOpts) string {
+	if opts.Profile != "" {
+		return opts.Profile
+	}
+	if opts.Flags != nil {
+		if f := opts.Flags.Lookup(ProfileFlag); f != nil && f.Changed {
+			return f.Value.String()
+		}
+	}
+	env := opts.ProfileEnv
+	if env == "" {
+		env = DefaultProfileEnv
+	}
+	return os.Getenv(env)
+}
+
+func configEnvKey(key string) string {
+	return strings.ToUpper("env_" + strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// mergeFile reads a configuration file of the search paths as a layer.
+func (s *configSource) mergeFile(layer, name string, paths []string, configType string) error {
+	file := viper.New()
+	for _, path := range paths {
+		file.AddConfigPath(path) // Search the root directory for the configuration file
+	}
+	file.SetConfigName(name) // Configuration fileName without the .TOML or .YAML extension
+	if configType != "" {
+		file.SetConfigType(configType)
+	}
+	if err := file.ReadInConfig(); err != nil {
+		return err
+	}
+	if err := s.viper.MergeConfigMap(file.AllSettings()); err != nil {
+		return err
+	}
+	s.files = append(s.files, file.ConfigFileUsed())
+	for _, key := range file.AllKeys() {
+		s.origins[key] = layer + ":" + file.ConfigFileUsed()
+	}
 	return nil
 }
+
+// bindFlags binds the changed flags named after a configuration key.
+func (s *configSource) bindFlags(flags *pflag.FlagSet, dest ConfigConstants) error {
+	if flags == nil {
+		return nil
+	}
+	keys := make(map[string]bool)
+	for _, key := range configKeys(reflect.TypeOf(dest), "") {
+		keys[key] = true
+	}
+	var err error
+	flags.Visit(func(f *pflag.Flag) {
+		key := strings.ToLower(f.Name)
+		if err != nil || !(keys[key] || s.viper.IsSet(key)) {
+			return
+		}
+		if err = s.viper.BindPFlag(key, f); err == nil {
+			s.origins[key] = LayerFlag + ":--" + f.Name
+		}
+	})
+	return err
+}
+
+// configKeys lists the dotted keys of a configuration struct after its mapstructure tags.
+func configKeys(t reflect.Type, prefix string) []string {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.SplitN(f.Tag.Get("mapstructure"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		key := strings.ToLower(prefix + name)
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			keys = append(keys, configKeys(ft, key+".")...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// settings flattens the merged configuration into dotted keys,
+// resolved secrets are redacted.
+func (s *configSource) settings() map[string]interface{} {
+	settings := make(map[string]interface{})
+	for _, key := range s.viper.AllKeys() {
+		if s.secrets[key] {
+			settings[key] = Redacted
+			continue
+		}
+		settings[key] = s.viper.Get(key)
+	}
+	return settings
+}
+
+func (s *configSource) dump(out io.Writer) error {
+	settings := s.settings()
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, key := range keys {
+		origin, ok := s.origins[key]
+		if !ok {
+			origin = "default"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t= %v\t# %s\n", key, settings[key], origin); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,141 @@
+/*  config_flags.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 14:20
+ */
+
+package mimir
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	secretType   = reflect.TypeOf(Secret(""))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// ConfigFlags defines a flag for every key of the configuration struct, named
+// after its mapstructure path (e.g. --app.port) and described by its
+// description tag, plus the --profile flag. The current values of cfg are the
+// flag defaults, secrets never are, see ConfigFlagsWithOpts for the defaults
+// of the other layers. Pass the flag set as ConfigOpts.Flags once parsed,
+// only the flags set on the command line override the other layers.
+func ConfigFlags(cfg ConfigConstants, flags *pflag.FlagSet) *pflag.FlagSet {
+	if flags == nil {
+		flags = pflag.NewFlagSet("config", pflag.ExitOnError)
+	}
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		defineConfigFlags(flags, v, "")
+	}
+	if flags.Lookup(ProfileFlag) == nil {
+		flags.String(ProfileFlag, "", fmt.Sprintf("configuration profile, overrides %s", DefaultProfileEnv))
+	}
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(os.Stderr, "Usage:")
+		ConfigUsage(os.Stderr, flags)
+	}
+	return flags
+}
+
+// ConfigFlagsWithOpts is ConfigFlags with the effective values of the file,
+// profile, dotenv and env layers of opts as the flag defaults. The profile is
+// the one of opts or of its environment variable, the --profile flag is not
+// parsed yet. The values are not validated, Config does it once the flags
+// are parsed.
+func ConfigFlagsWithOpts(opts ConfigOpts, configFunc ConfigFunc, flags *pflag.FlagSet) (*pflag.FlagSet, error) {
+	t := reflect.TypeOf(opts.Config)
+	if t == nil || t.Kind() != reflect.Ptr || reflect.ValueOf(opts.Config).IsNil() {
+		return nil, NotPointer
+	}
+	defaults := reflect.New(t.Elem())
+	defaults.Elem().Set(reflect.ValueOf(opts.Config).Elem())
+	opts.Flags = nil
+	opts.Validate = nil
+	if _, err := loadConfig(opts, configFunc, defaults.Interface()); err != nil {
+		if _, ok := err.(ConfigValidationError); !ok {
+			return nil, err
+		}
+	}
+	return ConfigFlags(defaults.Interface(), flags), nil
+}
+
+func defineConfigFlags(flags *pflag.FlagSet, v reflect.Value, prefix string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.SplitN(f.Tag.Get("mapstructure"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		key := strings.ToLower(prefix + name)
+		usage := f.Tag.Get("description")
+		if flags.Lookup(key) != nil {
+			continue
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv = reflect.Zero(fv.Type().Elem())
+			} else {
+				fv = fv.Elem()
+			}
+		}
+
+		switch {
+		case fv.Type() == durationType:
+			flags.Duration(key, time.Duration(fv.Int()), usage)
+		case fv.Type() == secretType:
+			flags.String(key, "", usage)
+		case fv.Type() == timeType:
+			continue
+		case fv.Kind() == reflect.Struct:
+			defineConfigFlags(flags, fv, key+".")
+		case fv.Kind() == reflect.String:
+			flags.String(key, fv.String(), usage)
+		case fv.Kind() == reflect.Bool:
+			flags.Bool(key, fv.Bool(), usage)
+		case fv.Kind() == reflect.Int64:
+			flags.Int64(key, fv.Int(), usage)
+		case fv.Kind() >= reflect.Int && fv.Kind() <= reflect.Int32:
+			flags.Int(key, int(fv.Int()), usage)
+		case fv.Kind() == reflect.Uint64:
+			flags.Uint64(key, fv.Uint(), usage)
+		case fv.Kind() >= reflect.Uint && fv.Kind() <= reflect.Uint32:
+			flags.Uint(key, uint(fv.Uint()), usage)
+		case fv.Kind() == reflect.Float32 || fv.Kind() == reflect.Float64:
+			flags.Float64(key, fv.Float(), usage)
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String:
+			flags.StringSlice(key, fv.Interface().([]string), usage)
+		}
+	}
+}
+
+// ConfigUsage prints every flag with its default, including the zero ones.
+func ConfigUsage(out io.Writer, flags *pflag.FlagSet) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	flags.VisitAll(func(f *pflag.Flag) {
+		_, _ = fmt.Fprintf(w, "  --%s %s\t(default %q)\t%s\n", f.Name, f.Value.Type(), f.DefValue, f.Usage)
+	})
+	_ = w.Flush()
+}
@@ -0,0 +1,111 @@
+/*  config_flags_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 14:45
+ */
+
+package mimir
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type flagConfig struct {
+	App struct {
+		Name      string         `mapstructure:"name" description:"application name"`
+		Port      int            `mapstructure:"port" description:"application port"`
+		Debug     bool           `mapstructure:"debug"`
+		SecretKey Secret         `mapstructure:"secret_key" description:"signing key"`
+		ExpireIn  *time.Duration `mapstructure:"expire_in"`
+		Hosts     []string       `mapstructure:"hosts"`
+	}
+	DB struct {
+		DsnMain string `mapstructure:"dsn_main"`
+	}
+}
+
+func TestConfigFlags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimir-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.config.yaml"),
+		[]byte("App:\n  name: laugh-tale\n  port: 8778\n  secret_key: sekret\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.config.staging.yaml"),
+		[]byte("App:\n  name: water-seven\n"), 0600))
+
+	cfg := &flagConfig{}
+	cfg.App.Name = "mimir"
+	flags := ConfigFlags(cfg, pflag.NewFlagSet("test", pflag.ContinueOnError))
+	assert.NoError(t, flags.Parse([]string{
+		"--app.port=9000",
+		"--app.expire_in=1m",
+		"--app.hosts=a,b",
+		"--profile=staging",
+	}))
+
+	err = Config(ConfigOpts{
+		Config:   cfg,
+		Filename: "app.config",
+		Paths:    []string{dir},
+		Flags:    flags,
+	}, func(v *viper.Viper) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "water-seven", cfg.App.Name)
+	assert.Equal(t, 9000, cfg.App.Port)
+	assert.Equal(t, "sekret", cfg.App.SecretKey.Value())
+	if assert.NotNil(t, cfg.App.ExpireIn) {
+		assert.Equal(t, time.Minute, *cfg.App.ExpireIn)
+	}
+	assert.Equal(t, []string{"a", "b"}, cfg.App.Hosts)
+
+	var out bytes.Buffer
+	ConfigUsage(&out, flags)
+	assert.Regexp(t, `--app\.name string\s+\(default "mimir"\)\s+application name`, out.String())
+	assert.Regexp(t, `--app\.port int\s+\(default "0"\)\s+application port`, out.String())
+	assert.Regexp(t, `--app\.secret_key string\s+\(default ""\)\s+signing key`, out.String())
+	assert.Contains(t, out.String(), "--db.dsn_main string")
+	assert.Contains(t, out.String(), "--profile string")
+}
+
+func TestConfigFlagsWithOpts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimir-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.config.yaml"),
+		[]byte("App:\n  port: 8778\n  secret_key: sekret\n"), 0600))
+
+	cfg := &flagConfig{}
+	cfg.App.Name = "mimir"
+	opts := ConfigOpts{Config: cfg, Filename: "app.config", Paths: []string{dir}}
+	noop := func(v *viper.Viper) error { return nil }
+	flags, err := ConfigFlagsWithOpts(opts, noop, pflag.NewFlagSet("test", pflag.ContinueOnError))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cfg.App.Port, "cfg is loaded by Config only")
+
+	// the defaults are the effective values but for the secrets
+	var out bytes.Buffer
+	ConfigUsage(&out, flags)
+	assert.Regexp(t, `--app\.name string\s+\(default "mimir"\)`, out.String())
+	assert.Regexp(t, `--app\.port int\s+\(default "8778"\)`, out.String())
+	assert.Regexp(t, `--app\.secret_key string\s+\(default ""\)`, out.String())
+
+	assert.NoError(t, flags.Parse(nil))
+	opts.Flags = flags
+	assert.NoError(t, Config(opts, noop))
+	assert.Equal(t, 8778, cfg.App.Port)
+	assert.Equal(t, "sekret", cfg.App.SecretKey.Value())
+
+	_, err = ConfigFlagsWithOpts(ConfigOpts{Config: flagConfig{}}, noop, nil)
+	assert.Equal(t, NotPointer, err)
+}
@@ -9,11 +9,16 @@
 package mimir
 
 import (
+	"bytes"
 	"errors"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -110,3 +115,99 @@ func TestFailConfigFunc(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestConfigValidation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimir-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, "app.config.yaml"),
+		[]byte("App:\n  port: 0\nDB:\n  dsn_main: host=localhost\n"),
+		0600,
+	))
+
+	cfg := struct {
+		App struct {
+			Port int `mapstructure:"port" validate:"port"`
+		}
+		DB struct {
+			DsnMain string `mapstructure:"dsn_main" validate:"required"`
+			DsnRead string `mapstructure:"dsn_read" validate:"required"`
+		}
+	}{}
+	err = Config(ConfigOpts{
+		Config:   &cfg,
+		Filename: "app.config",
+		Paths:    []string{dir},
+	}, func(v *viper.Viper) error {
+		return nil
+	})
+
+	var errs ConfigValidationError
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.Equal(t, []string{"app.port", "db.dsn_read"}, []string{errs[0].Field, errs[1].Field})
+	}
+	assert.EqualError(t, err, `invalid configuration: app.port failed on port (value "0"), db.dsn_read failed on required (value "")`)
+}
+
+func TestConfigProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimir-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer os.Clearenv()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.config.yaml"),
+		[]byte("App:\n  name: laugh-tale\n  port: 8778\n  debug: true\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.config.production.yaml"),
+		[]byte("App:\n  port: 80\n  debug: false\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".env"),
+		[]byte("APP.NAME=marineford\n"), 0600))
+	_ = os.Setenv("APP_PROFILE", "production")
+	_ = os.Setenv("ENV_APP_DEBUG", "true")
+
+	cfg := struct {
+		App struct {
+			Name  string `mapstructure:"name"`
+			Port  int    `mapstructure:"port"`
+			Debug bool   `mapstructure:"debug"`
+		}
+	}{}
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Int("app.port", 0, "application port")
+	flags.Bool("verbose", false, "not a configuration key")
+	assert.NoError(t, flags.Parse([]string{"--app.port=9000", "--verbose"}))
+
+	opts := ConfigOpts{
+		Config:   &cfg,
+		Filename: "app.config",
+		Paths:    []string{dir},
+		Flags:    flags,
+	}
+	err = Config(opts, func(v *viper.Viper) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "marineford", cfg.App.Name)
+	assert.Equal(t, 9000, cfg.App.Port)
+	assert.True(t, cfg.App.Debug)
+
+	var out bytes.Buffer
+	assert.NoError(t, DumpConfig(&out, opts, func(v *viper.Viper) error {
+		return nil
+	}))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Regexp(t, `^app\.debug\s+= true\s+# env:ENV_APP_DEBUG$`, lines[0])
+		assert.Regexp(t, `^app\.name\s+= marineford\s+# dotenv:.*\.env$`, lines[1])
+		assert.Regexp(t, `^app\.port\s+= 9000\s+# flag:--app\.port$`, lines[2])
+	}
+
+	// the base file is used as it is without profile
+	opts.Flags = nil
+	_ = os.Unsetenv("APP_PROFILE")
+	_ = os.Unsetenv("ENV_APP_DEBUG")
+	out.Reset()
+	assert.NoError(t, DumpConfig(&out, opts, func(v *viper.Viper) error {
+		return nil
+	}))
+	assert.Regexp(t, `app\.port\s+= 8778\s+# file:.*app\.config\.yaml`, out.String())
+}
@@ -0,0 +1,212 @@
+/*  config_watcher.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 11:48
+ */
+
+package mimir
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DefaultWatchInterval = 5 * time.Second
+
+// ConfigSubscriber is notified with the previous and the new configuration
+// after a successful reload.
+type ConfigSubscriber func(old, new ConfigConstants)
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+type configValue struct {
+	config ConfigConstants
+}
+
+// ConfigWatcher keeps the configuration up to date with its files. Every reload
+// unmarshal into a fresh copy of ConfigOpts.Config which is swapped atomically
+// once it has been validated.
+type ConfigWatcher struct {
+	mu          sync.Mutex
+	opts        ConfigOpts
+	configFunc  ConfigFunc
+	logger      Logging
+	current     atomic.Value
+	settings    map[string]interface{}
+	stamps      map[string]fileStamp
+	subscribers []ConfigSubscriber
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// WatchConfig loads the configuration into opts.Config and polls its files
+// every opts.WatchInterval. opts.Config must be a pointer to a struct.
+func WatchConfig(opts ConfigOpts, configFunc ConfigFunc) (*ConfigWatcher, error) {
+	if t := reflect.TypeOf(opts.Config); t == nil || t.Kind() != reflect.Ptr {
+		return nil, NotPointer
+	}
+	if opts.WatchInterval <= 0 {
+		opts.WatchInterval = DefaultWatchInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = With(Field("config", opts.Filename))
+	}
+
+	source, err := loadConfig(opts, configFunc, opts.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &ConfigWatcher{
+		opts:       opts,
+		configFunc: configFunc,
+		logger:     opts.Logger,
+		settings:   source.settings(),
+		stamps:     stampFiles(source.files),
+		done:       make(chan struct{}),
+	}
+	w.current.Store(configValue{config: opts.Config})
+
+	go w.watch()
+	return w, nil
+}
+
+// Get returns the current configuration, it has the same type as ConfigOpts.Config.
+// The returned value must be treated as read only.
+func (w *ConfigWatcher) Get() ConfigConstants {
+	return w.current.Load().(configValue).config
+}
+
+// Subscribe registers a subscriber notified on every successful reload.
+func (w *ConfigWatcher) Subscribe(fn ConfigSubscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload reads the configuration again, a configuration that fails to load
+// or to validate is rejected and the current one is kept. The subscribers
+// are notified outside of the lock, they may use the watcher.
+func (w *ConfigWatcher) Reload() error {
+	old, fresh, subscribers, err := w.reload()
+	if err != nil {
+		return err
+	}
+	for _, fn := range subscribers {
+		fn(old, fresh)
+	}
+	return nil
+}
+
+func (w *ConfigWatcher) reload() (ConfigConstants, ConfigConstants, []ConfigSubscriber, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	fresh := reflect.New(reflect.TypeOf(w.opts.Config).Elem()).Interface()
+	source, err := loadConfig(w.opts, w.configFunc, fresh)
+	if source != nil {
+		w.stamps = stampFiles(source.files)
+	}
+	if err != nil {
+		if source != nil {
+			w.logger.Warn("Config reload diff", w.logger.Field("diff", configDiff(w.settings, source.settings())))
+		}
+		w.logger.Errorf("Config reload rejected: %v", err)
+		return nil, nil, nil, err
+	}
+
+	old := w.Get()
+	w.settings = source.settings()
+	w.current.Store(configValue{config: fresh})
+	w.logger.Infof("Config %s reloaded", w.opts.Filename)
+
+	subscribers := make([]ConfigSubscriber, len(w.subscribers))
+	copy(subscribers, w.subscribers)
+	return old, fresh, subscribers, nil
+}
+
+// Close stops watching the configuration files.
+func (w *ConfigWatcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+	})
+}
+
+func (w *ConfigWatcher) watch() {
+	ticker := time.NewTicker(w.opts.WatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			if w.changed() {
+				_ = w.Reload()
+			}
+		}
+	}
+}
+
+func (w *ConfigWatcher) changed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for file, stamp := range w.stamps {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(stamp.modTime) || info.Size() != stamp.size {
+			return true
+		}
+	}
+	return false
+}
+
+func stampFiles(files []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			stamps[file] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+// configDiff lists the keys whose value differs between two flattened configurations.
+func configDiff(old, new map[string]interface{}) []string {
+	keys := make(map[string]struct{}, len(old)+len(new))
+	for k := range old {
+		keys[k] = struct{}{}
+	}
+	for k := range new {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	diff := make([]string, 0)
+	for _, k := range sorted {
+		o, inOld := old[k]
+		n, inNew := new[k]
+		switch {
+		case !inOld:
+			diff = append(diff, fmt.Sprintf("+ %s: %v", k, n))
+		case !inNew:
+			diff = append(diff, fmt.Sprintf("- %s: %v", k, o))
+		case !reflect.DeepEqual(o, n):
+			diff = append(diff, fmt.Sprintf("~ %s: %v -> %v", k, o, n))
+		}
+	}
+	return diff
+}
@@ -0,0 +1,140 @@
+/*  config_watcher_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 12:10
+ */
+
+package mimir
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type watchedConfig struct {
+	App struct {
+		Name string `mapstructure:"name"`
+		Port int    `mapstructure:"port"`
+	}
+}
+
+func writeConfigFile(t *testing.T, file, content string, mod time.Time) {
+	assert.NoError(t, ioutil.WriteFile(file, []byte(content), 0600))
+	assert.NoError(t, os.Chtimes(file, mod, mod))
+}
+
+func TestWatchConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimir-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "app.config.yaml")
+	now := time.Now()
+	writeConfigFile(t, file, "App:\n  name: laugh-tale\n  port: 8778\n", now)
+
+	cfg := &watchedConfig{}
+	watcher, err := WatchConfig(ConfigOpts{
+		Config:        cfg,
+		Filename:      "app.config",
+		Paths:         []string{dir},
+		WatchInterval: 5 * time.Millisecond,
+		Validate: func(c ConfigConstants) error {
+			if c.(*watchedConfig).App.Port == 0 {
+				return fmt.Errorf("app.port is required")
+			}
+			return nil
+		},
+	}, func(v *viper.Viper) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	defer watcher.Close()
+	assert.Equal(t, cfg, watcher.Get())
+
+	changes := make(chan [2]*watchedConfig, 1)
+	watcher.Subscribe(func(old, new ConfigConstants) {
+		changes <- [2]*watchedConfig{old.(*watchedConfig), new.(*watchedConfig)}
+	})
+
+	writeConfigFile(t, file, "App:\n  name: laugh-tale\n  port: 9000\n", now.Add(time.Second))
+	select {
+	case change := <-changes:
+		assert.Equal(t, 8778, change[0].App.Port)
+		assert.Equal(t, 9000, change[1].App.Port)
+	case <-time.After(time.Second):
+		assert.Fail(t, "config change was never notified")
+	}
+	assert.Equal(t, 9000, watcher.Get().(*watchedConfig).App.Port)
+	assert.Equal(t, 8778, cfg.App.Port, "the initial configuration is never mutated")
+
+	// rejected edits keep the current configuration
+	writeConfigFile(t, file, "App:\n  name: laugh-tale\n  port: 0\n", now.Add(2*time.Second))
+	assert.Error(t, watcher.Reload())
+	writeConfigFile(t, file, "App:\n  name: [laugh-tale\n", now.Add(3*time.Second))
+	assert.Error(t, watcher.Reload())
+	assert.Equal(t, 9000, watcher.Get().(*watchedConfig).App.Port)
+}
+
+func TestWatchConfigSubscriberUsesWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimir-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeConfigFile(t, filepath.Join(dir, "app.config.yaml"), "App:\n  name: laugh-tale\n  port: 8778\n", time.Now())
+
+	watcher, err := WatchConfig(ConfigOpts{
+		Config:        &watchedConfig{},
+		Filename:      "app.config",
+		Paths:         []string{dir},
+		WatchInterval: time.Hour,
+	}, func(v *viper.Viper) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	defer watcher.Close()
+
+	// a subscriber may subscribe and read while it is notified
+	watcher.Subscribe(func(old, new ConfigConstants) {
+		watcher.Subscribe(func(old, new ConfigConstants) {})
+		_ = watcher.Get()
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Reload()
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "reload deadlocked on the subscriber")
+	}
+}
+
+func TestWatchConfigNotPointer(t *testing.T) {
+	_, err := WatchConfig(ConfigOpts{
+		Config:   watchedConfig{},
+		Filename: "app.config.test",
+		Paths:    []string{"."},
+	}, func(v *viper.Viper) error {
+		return nil
+	})
+	assert.Equal(t, NotPointer, err)
+}
+
+func TestConfigDiff(t *testing.T) {
+	diff := configDiff(
+		map[string]interface{}{"app.port": 8778, "app.name": "laugh-tale", "app.debug": true},
+		map[string]interface{}{"app.port": 0, "app.name": "laugh-tale", "db.dsn_main": "host=localhost"},
+	)
+	assert.Equal(t, []string{
+		"- app.debug: true",
+		"~ app.port: 8778 -> 0",
+		"+ db.dsn_main: host=localhost",
+	}, diff)
+}
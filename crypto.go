@@ -9,14 +9,21 @@
 package mimir
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"hash"
-	"strconv"
+	"io"
 	"strings"
+	"sync"
 
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
 	"golang.org/x/crypto/pbkdf2"
 )
 
@@ -72,44 +79,218 @@ func HashPassword(password, salt string) string {
 	)
 }
 
+// HashPasswordAuto hashes the password like HashPassword with a random salt
+// drawn from crypto/rand.
+func HashPasswordAuto(password string) (string, error) {
+	return (&PBKDF2Hasher{}).Hash(password)
+}
+
+// VerifyPassword checks the password against a hash of any algorithm
+// of DefaultPasswordHashers.
 func VerifyPassword(hashpassword, password string) (bool, error) {
-	// only pbkdf2 supported
-	if !strings.HasPrefix(hashpassword, "$pbkdf2-") {
-		return false, fmt.Errorf("invalid hashPass")
-	}
-	// five fields expected: $pbkdf2-digest$rounds$salt$checksum
-	fields := strings.Split(hashpassword, "$")
-	if len(fields) != 5 {
-		return false, fmt.Errorf("invalid hashPass format")
-	}
-	// extract digest
-	hdr := strings.Split(fields[1], "-")
-	if len(hdr) != 2 {
-		return false, fmt.Errorf("invalid digest")
-	}
-	var (
-		keyLen   int
-		hashFunc func() hash.Hash
-	)
-	switch hdr[1] {
-	case "sha256":
-		keyLen = sha256.Size
-		hashFunc = sha256.New
-	case "sha512":
-		keyLen = sha512.Size
-		hashFunc = sha512.New
-	default:
-		return false, fmt.Errorf("invalid hashPass func")
-	}
-	// get remaining fields
-	rounds, err := strconv.Atoi(fields[2])
+	return DefaultPasswordHashers.Verify(hashpassword, password)
+}
+
+// CipherAlgorithm is the AEAD of an encrypted envelope.
+type CipherAlgorithm byte
+
+const (
+	AESGCM CipherAlgorithm = iota + 1
+	XChaCha20Poly1305
+)
+
+// envelopeVersion is the first byte of an encrypted envelope,
+// followed by the algorithm, the key ID length, the key ID, the nonce
+// and the sealed plaintext.
+const envelopeVersion byte = 1
+
+var (
+	ErrDecrypt    = errors.New("cipher: message authentication failed")
+	ErrUnknownKey = errors.New("cipher: unknown key")
+)
+
+func (a CipherAlgorithm) String() string {
+	switch a {
+	case AESGCM:
+		return "aes-gcm"
+	case XChaCha20Poly1305:
+		return "xchacha20-poly1305"
+	}
+	return fmt.Sprintf("cipher(%d)", byte(a))
+}
+
+func (a CipherAlgorithm) aead(secret []byte) (cipher.AEAD, error) {
+	switch a {
+	case AESGCM:
+		block, err := aes.NewCipher(secret)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case XChaCha20Poly1305:
+		return chacha20poly1305.NewX(secret)
+	}
+	return nil, fmt.Errorf("cipher: unsupported algorithm %v", a)
+}
+
+// Key is an encryption key, AES-GCM takes a 16, 24 or 32 bytes secret
+// and XChaCha20-Poly1305 a 32 bytes one.
+type Key struct {
+	ID        string
+	Algorithm CipherAlgorithm
+	Secret    []byte
+}
+
+// Encrypt seals the plaintext with AES-GCM into a versioned envelope,
+// aad is authenticated but not encrypted.
+func Encrypt(key, plaintext, aad []byte) ([]byte, error) {
+	return Key{Algorithm: AESGCM, Secret: key}.Encrypt(plaintext, aad)
+}
+
+// Decrypt opens an envelope of Encrypt.
+func Decrypt(key, envelope, aad []byte) ([]byte, error) {
+	return Key{Algorithm: AESGCM, Secret: key}.Decrypt(envelope, aad)
+}
+
+func (k Key) Encrypt(plaintext, aad []byte) ([]byte, error) {
+	if len(k.ID) > 255 {
+		return nil, fmt.Errorf("cipher: key id longer than 255 bytes")
+	}
+	aead, err := k.Algorithm.aead(k.Secret)
 	if err != nil {
-		return false, fmt.Errorf("invalid hashPass roound")
+		return nil, err
 	}
-	salt, err := PassLibBase64Decode(fields[3])
+	header := append([]byte{envelopeVersion, byte(k.Algorithm), byte(len(k.ID))}, k.ID...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(append(out, header...), nonce...)
+	// the header is authenticated along with aad
+	return aead.Seal(out, nonce, plaintext, append(header, aad...)), nil
+}
+
+func (k Key) Decrypt(envelope, aad []byte) ([]byte, error) {
+	header, alg, id, err := parseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	if alg != k.Algorithm || id != k.ID {
+		return nil, ErrUnknownKey
+	}
+	aead, err := k.Algorithm.aead(k.Secret)
 	if err != nil {
-		return false, fmt.Errorf("invalid hashPass salt")
+		return nil, err
 	}
-	key := pbkdf2.Key([]byte(password), salt, rounds, keyLen, hashFunc)
-	return fields[4] == PassLibBase64Encode(key), nil
+	body := envelope[len(header):]
+	if len(body) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+	nonce, sealed := body[:aead.NonceSize()], body[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, append(header[:len(header):len(header)], aad...))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// EnvelopeKeyID returns the key ID recorded in an envelope.
+func EnvelopeKeyID(envelope []byte) (string, error) {
+	_, _, id, err := parseEnvelope(envelope)
+	return id, err
+}
+
+func parseEnvelope(envelope []byte) ([]byte, CipherAlgorithm, string, error) {
+	if len(envelope) < 3 {
+		return nil, 0, "", fmt.Errorf("cipher: envelope too short")
+	}
+	if envelope[0] != envelopeVersion {
+		return nil, 0, "", fmt.Errorf("cipher: unsupported envelope version %d", envelope[0])
+	}
+	end := 3 + int(envelope[2])
+	if len(envelope) < end {
+		return nil, 0, "", fmt.Errorf("cipher: envelope too short")
+	}
+	return envelope[:end], CipherAlgorithm(envelope[1]), string(envelope[3:end]), nil
+}
+
+// Keyring encrypts with its primary key and decrypts with any of its keys,
+// rotating adds a new primary key while the old ones still decrypt.
+type Keyring struct {
+	mu      sync.RWMutex
+	primary string
+	keys    map[string]Key
+}
+
+func NewKeyring(primary Key, others ...Key) *Keyring {
+	k := &Keyring{keys: make(map[string]Key)}
+	for _, key := range others {
+		k.keys[key.ID] = key
+	}
+	k.Rotate(primary)
+	return k
+}
+
+// Rotate adds the key and makes it the primary key.
+func (k *Keyring) Rotate(key Key) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[key.ID] = key
+	k.primary = key.ID
+}
+
+// Remove drops a retired key, the primary key cannot be removed.
+func (k *Keyring) Remove(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if id == k.primary {
+		return fmt.Errorf("cipher: cannot remove the primary key %q", id)
+	}
+	delete(k.keys, id)
+	return nil
+}
+
+func (k *Keyring) Encrypt(plaintext, aad []byte) ([]byte, error) {
+	k.mu.RLock()
+	key := k.keys[k.primary]
+	k.mu.RUnlock()
+	return key.Encrypt(plaintext, aad)
+}
+
+// Decrypt opens the envelope with the key it was sealed with.
+func (k *Keyring) Decrypt(envelope, aad []byte) ([]byte, error) {
+	id, err := EnvelopeKeyID(envelope)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.RLock()
+	key, ok := k.keys[id]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key.Decrypt(envelope, aad)
+}
+
+// DeriveKey derives a key of length bytes from the secret with HKDF-SHA256,
+// info binds the key to its purpose, e.g. "pii-column".
+func DeriveKey(secret, salt, info []byte, length int) ([]byte, error) {
+	key := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// HMACSHA256 returns the HMAC-SHA256 of message with key.
+func HMACSHA256(key, message []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// VerifyHMACSHA256 compares the HMAC-SHA256 of message with mac in constant time.
+func VerifyHMACSHA256(key, message, mac []byte) bool {
+	return hmac.Equal(mac, HMACSHA256(key, message))
 }
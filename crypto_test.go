@@ -9,6 +9,8 @@
 package mimir
 
 import (
+	"bytes"
+	"encoding/hex"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -53,3 +55,129 @@ func TestPassLibBase64(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyPasswordPassLib(t *testing.T) {
+	// sha1 and sha256 vectors come from the passlib test suite, the sha512
+	// ones were made with passlib's format over python hashlib.pbkdf2_hmac
+	var vectors = []struct {
+		title    string
+		password string
+		hash     string
+	}{
+		{"pbkdf2 sha1", "password", "$pbkdf2$1212$OB.dtnSEXZK8U5cgxU/GYQ$y5LKPOplRmok7CZp/aqVDVg8zGI"},
+		{"pbkdf2 sha256", "password", "$pbkdf2-sha256$1212$4vjV83LKPjQzk31VI4E0Vw$hsYF68OiOUPdDZ1Fg.fJPeq1h/gXXY7acBp9/6c.tmQ"},
+		{"pbkdf2 sha512", "password", "$pbkdf2-sha512$25000$RHY0Fr3IDMSVO/RSZyb5ow$40uTkfVnD8nfKh0XhyCDeqyeZ4VjzwBEBxo9cD9o3PVvOOaiTYaRexAq.AEnlUGdWvRmUQgd6f1pSpeBXZgOLg"},
+		{"pbkdf2 sha512 unicode", "Hello, 世界", "$pbkdf2-sha512$25000$RHY0Fr3IDMSVO/RSZyb5ow$3Dab4diwMBAUR2uVK.fkvwFjNdI/JX4LWiB.QulatNWwWH9Ibwn2ai07ybGhklG/tTR7kWFIR9y433SqYziO5A"},
+	}
+	for _, tt := range vectors {
+		tt := tt // pin it
+		t.Run(tt.title, func(t *testing.T) {
+			ok, err := VerifyPassword(tt.hash, tt.password)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = VerifyPassword(tt.hash, tt.password+"!")
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+
+	_, err := VerifyPassword("$pbkdf2-sha512$25000$RHY0Fr3IDMSVO/RSZyb5ow$40uTkf", "password")
+	assert.Error(t, err, "a truncated checksum is rejected")
+}
+
+func TestHashPasswordAuto(t *testing.T) {
+	hash, err := HashPasswordAuto("sekret")
+	assert.NoError(t, err)
+	other, err := HashPasswordAuto("sekret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+	assert.Regexp(t, `^\$pbkdf2-sha512\$25000\$[./A-Za-z0-9]{22}\$[./A-Za-z0-9]{86}$`, hash)
+
+	ok, err := VerifyPassword(hash, "sekret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	sha1, err := (&PBKDF2Hasher{Digest: "sha1", Rounds: 1000}).Hash("sekret")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^\$pbkdf2\$1000\$`, sha1)
+	ok, err = VerifyPassword(sha1, "sekret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, (&PBKDF2Hasher{Digest: "sha1"}).NeedsRehash(sha1))
+}
+
+func TestEncrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	envelope, err := Encrypt(key, []byte("4111 1111 1111 1111"), []byte("user:42"))
+	assert.NoError(t, err)
+
+	plaintext, err := Decrypt(key, envelope, []byte("user:42"))
+	assert.NoError(t, err)
+	assert.Equal(t, "4111 1111 1111 1111", string(plaintext))
+
+	_, err = Decrypt(key, envelope, []byte("user:43"))
+	assert.Equal(t, ErrDecrypt, err, "the aad is authenticated")
+
+	tampered := append([]byte(nil), envelope...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = Decrypt(key, tampered, []byte("user:42"))
+	assert.Equal(t, ErrDecrypt, err)
+
+	for _, encode := range []struct {
+		encode func([]byte) string
+		decode func(string) ([]byte, error)
+	}{
+		{Base64Encode, Base64Decode},
+		{PassLibBase64Encode, PassLibBase64Decode},
+	} {
+		decoded, err := encode.decode(encode.encode(envelope))
+		assert.NoError(t, err)
+		plaintext, err = Decrypt(key, decoded, []byte("user:42"))
+		assert.NoError(t, err)
+		assert.Equal(t, "4111 1111 1111 1111", string(plaintext))
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	old := Key{ID: "2019", Algorithm: AESGCM, Secret: bytes.Repeat([]byte{1}, 16)}
+	keyring := NewKeyring(old)
+	envelope, err := keyring.Encrypt([]byte("sekret"), nil)
+	assert.NoError(t, err)
+
+	keyring.Rotate(Key{ID: "2020", Algorithm: XChaCha20Poly1305, Secret: bytes.Repeat([]byte{2}, 32)})
+	rotated, err := keyring.Encrypt([]byte("sekret"), nil)
+	assert.NoError(t, err)
+
+	id, err := EnvelopeKeyID(rotated)
+	assert.NoError(t, err)
+	assert.Equal(t, "2020", id)
+
+	for _, e := range [][]byte{envelope, rotated} {
+		plaintext, err := keyring.Decrypt(e, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "sekret", string(plaintext))
+	}
+
+	assert.Error(t, keyring.Remove("2020"))
+	assert.NoError(t, keyring.Remove("2019"))
+	_, err = keyring.Decrypt(envelope, nil)
+	assert.Equal(t, ErrUnknownKey, err)
+}
+
+func TestDeriveKey(t *testing.T) {
+	// RFC 5869 test case 1
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	key, err := DeriveKey(bytes.Repeat([]byte{0x0b}, 22), salt, info, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865", hex.EncodeToString(key))
+}
+
+func TestHMACSHA256(t *testing.T) {
+	// RFC 4231 test case 2
+	mac := HMACSHA256([]byte("Jefe"), []byte("what do ya want for nothing?"))
+	assert.Equal(t, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", hex.EncodeToString(mac))
+	assert.True(t, VerifyHMACSHA256([]byte("Jefe"), []byte("what do ya want for nothing?"), mac))
+	assert.False(t, VerifyHMACSHA256([]byte("Jefe"), []byte("what do ya want for nothing!"), mac))
+}
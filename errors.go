@@ -0,0 +1,210 @@
+/*  errors.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 22:50
+ */
+
+package mimir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DomainError is an error shared by the HTTP and the gRPC handlers, it is
+// written as the Respond envelope by APIStatusError and returned as a
+// status with details by the gRPC handlers.
+//
+//	return mimir.NewDomainError(codes.NotFound, "BOOKING_NOT_FOUND", "booking not found")
+type DomainError struct {
+	Code    codes.Code
+	Message string
+	// Reason, Domain and Metadata are sent as google.rpc.ErrorInfo.
+	Reason   string
+	Domain   string
+	Metadata map[string]string
+	// Violations are sent as google.rpc.BadRequest.
+	Violations []ErrorValidator
+	// RetryAfter is sent as google.rpc.RetryInfo and as the Retry-After header.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func NewDomainError(code codes.Code, reason, message string) *DomainError {
+	return &DomainError{Code: code, Reason: reason, Message: message}
+}
+
+// NewValidationError is the invalid argument error of the Validate violations.
+func NewValidationError(violations []ErrorValidator) *DomainError {
+	return &DomainError{
+		Code:       codes.InvalidArgument,
+		Message:    StatusText(StatusBadRequest),
+		Violations: violations,
+	}
+}
+
+func (e *DomainError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s %s: %s", e.Code, e.Reason, e.message())
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.message())
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DomainError) message() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return StatusText(e.HTTPStatus())
+}
+
+// codeStatus follows the HTTP mapping of google/rpc/code.proto on the
+// statuses of statusMap.
+var codeStatus = map[codes.Code]int{
+	codes.OK:                 StatusSuccess,
+	codes.Canceled:           StatusRequestTimeout,
+	codes.Unknown:            StatusInternalError,
+	codes.InvalidArgument:    StatusBadRequest,
+	codes.DeadlineExceeded:   StatusGatewayTimeoutError,
+	codes.NotFound:           StatusNotFound,
+	codes.AlreadyExists:      StatusConflict,
+	codes.PermissionDenied:   StatusForbidden,
+	codes.ResourceExhausted:  StatusTooManyRequests,
+	codes.FailedPrecondition: StatusUnProcess,
+	codes.Aborted:            StatusConflict,
+	codes.OutOfRange:         StatusBadRequest,
+	codes.Unimplemented:      StatusNotImplementedError,
+	codes.Internal:           StatusInternalError,
+	codes.Unavailable:        StatusServiceUnavailableError,
+	codes.DataLoss:           StatusInternalError,
+	codes.Unauthenticated:    StatusUnauthorized,
+}
+
+// HTTPStatus is the statusMap status of the code.
+func (e *DomainError) HTTPStatus() int {
+	if code, ok := codeStatus[e.Code]; ok {
+		return code
+	}
+	return StatusInternalError
+}
+
+// Meta is the envelope meta of the error.
+func (e *DomainError) Meta() Meta {
+	code := e.HTTPStatus()
+	return Meta{
+		Code:    strconv.Itoa(code),
+		Type:    StatusCode(code),
+		Message: e.message(),
+	}
+}
+
+// GRPCStatus makes the error a gRPC status with its details, status.Convert
+// and the gRPC server call it on the returned errors.
+func (e *DomainError) GRPCStatus() *status.Status {
+	s := status.New(e.Code, e.message())
+	var details []proto.Message
+	if e.Reason != "" || e.Domain != "" || len(e.Metadata) > 0 {
+		details = append(details, &errdetails.ErrorInfo{Reason: e.Reason, Domain: e.Domain, Metadata: e.Metadata})
+	}
+	if len(e.Violations) > 0 {
+		bad := &errdetails.BadRequest{}
+		for _, v := range e.Violations {
+			bad.FieldViolations = append(bad.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Message,
+			})
+		}
+		details = append(details, bad)
+	}
+	if e.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(e.RetryAfter)})
+	}
+	if len(details) == 0 {
+		return s
+	}
+	if ds, err := s.WithDetails(details...); err == nil {
+		return ds
+	}
+	return s
+}
+
+// AsDomainError finds the DomainError in the chain of err, a gRPC status
+// error is converted with its details.
+func AsDomainError(err error) (*DomainError, bool) {
+	var e *DomainError
+	if errors.As(err, &e) {
+		return e, true
+	}
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		return errorFromStatus(se.GRPCStatus(), err), true
+	}
+	return nil, false
+}
+
+// DomainErrorFrom converts any error, the breaker rejections are unavailable,
+// the timeouts deadline exceeded and the unknown errors are unknown.
+func DomainErrorFrom(err error) *DomainError {
+	if err == nil {
+		return nil
+	}
+	if e, ok := AsDomainError(err); ok {
+		return e
+	}
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrMaxConcurrency):
+		code = codes.Unavailable
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	}
+	return &DomainError{Code: code, Message: err.Error(), Err: err}
+}
+
+func errorFromStatus(s *status.Status, err error) *DomainError {
+	e := &DomainError{Code: s.Code(), Message: s.Message(), Err: err}
+	for _, detail := range s.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			e.Reason, e.Domain, e.Metadata = d.Reason, d.Domain, d.Metadata
+		case *errdetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				e.Violations = append(e.Violations, ErrorValidator{Field: v.Field, Message: v.Description})
+			}
+		case *errdetails.RetryInfo:
+			if delay, err := ptypes.Duration(d.RetryDelay); err == nil {
+				e.RetryAfter = delay
+			}
+		}
+	}
+	return e
+}
+
+// writeError writes the envelope of a domain error, the violations are the data.
+func (r *Respond) writeError(w http.ResponseWriter, req *http.Request, e *DomainError) *responseWriter {
+	code := e.HTTPStatus()
+	if e.RetryAfter > 0 {
+		seconds := int64((e.RetryAfter + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+	r.Errors(e.Meta())
+	if len(e.Violations) > 0 {
+		r.Body(e.Violations)
+	}
+	return Status(w, req, code, r)
+}
@@ -0,0 +1,134 @@
+/*  errors_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 23:10
+ */
+
+package mimir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestDomainErrorHTTP(t *testing.T) {
+	violations := []ErrorValidator{{Field: "email", Tag: "email", Message: "Invalid Type foo for input email"}}
+	retry := NewDomainError(codes.ResourceExhausted, "QUOTA", "quota exceeded")
+	retry.RetryAfter = 1500 * time.Millisecond
+
+	cases := []struct {
+		name    string
+		err     error
+		code    int
+		typ     string
+		message string
+	}{
+		{"not found", NewDomainError(codes.NotFound, "BOOKING_NOT_FOUND", "booking not found"), StatusNotFound, "STATUS_NOT_FOUND", "booking not found"},
+		{"wrapped", fmt.Errorf("load: %w", NewDomainError(codes.AlreadyExists, "", "")), StatusConflict, "STATUS_CONFLICT", StatusText(StatusConflict)},
+		{"validation", NewValidationError(violations), StatusBadRequest, "STATUS_BAD_REQUEST", StatusText(StatusBadRequest)},
+		{"grpc status", status.Error(codes.PermissionDenied, "no access"), StatusForbidden, "STATUS_FORBIDDEN", "no access"},
+		{"retry", retry, StatusTooManyRequests, "STATUS_TOO_MANY_REQUESTS", "quota exceeded"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			Response(r).APIStatusError(w, r, c.err).WriteJSON()
+			assert.Equal(t, c.code, w.Code)
+
+			var env struct {
+				Meta []Meta          `json:"meta"`
+				Data json.RawMessage `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+			require.Len(t, env.Meta, 1)
+			assert.Equal(t, Meta{Code: fmt.Sprint(c.code), Type: c.typ, Message: c.message}, env.Meta[0])
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	Response(r).APIStatusError(w, r, retry).WriteJSON()
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	w = httptest.NewRecorder()
+	Response(r).APIStatusError(w, r, NewValidationError(violations)).WriteJSON()
+	var env struct {
+		Data []ErrorValidator `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.Equal(t, violations, env.Data)
+}
+
+func TestDomainErrorFrom(t *testing.T) {
+	assert.Nil(t, DomainErrorFrom(nil))
+	assert.Equal(t, codes.Unavailable, DomainErrorFrom(fmt.Errorf("pay: %w", ErrCircuitOpen)).Code)
+	assert.Equal(t, codes.DeadlineExceeded, DomainErrorFrom(context.DeadlineExceeded).Code)
+	assert.Equal(t, codes.Canceled, DomainErrorFrom(context.Canceled).Code)
+
+	plain := fmt.Errorf("disk full")
+	e := DomainErrorFrom(plain)
+	assert.Equal(t, codes.Unknown, e.Code)
+	assert.Equal(t, StatusInternalError, e.HTTPStatus())
+	assert.True(t, errors.Is(e, plain))
+}
+
+func TestDomainErrorGRPCStatus(t *testing.T) {
+	in := &DomainError{
+		Code:       codes.InvalidArgument,
+		Message:    "invalid booking",
+		Reason:     "BOOKING_INVALID",
+		Domain:     "booking.mimir",
+		Metadata:   map[string]string{"booking": "42"},
+		Violations: []ErrorValidator{{Field: "date", Message: "date is in the past"}},
+		RetryAfter: 3 * time.Second,
+	}
+	s := in.GRPCStatus()
+	assert.Equal(t, codes.InvalidArgument, s.Code())
+	assert.Len(t, s.Details(), 3)
+	assert.IsType(t, &errdetails.ErrorInfo{}, s.Details()[0])
+
+	// through the wire
+	buf, err := proto.Marshal(s.Proto())
+	require.NoError(t, err)
+	var decoded spb.Status
+	require.NoError(t, proto.Unmarshal(buf, &decoded))
+
+	out, ok := AsDomainError(status.FromProto(&decoded).Err())
+	require.True(t, ok)
+	assert.Equal(t, in.Code, out.Code)
+	assert.Equal(t, in.Message, out.Message)
+	assert.Equal(t, in.Reason, out.Reason)
+	assert.Equal(t, in.Domain, out.Domain)
+	assert.Equal(t, in.Metadata, out.Metadata)
+	assert.Equal(t, in.Violations, out.Violations)
+	assert.Equal(t, in.RetryAfter, out.RetryAfter)
+}
+
+func TestServerErrorsInterceptor(t *testing.T) {
+	conn, stop := newBufServer(t, rpc.ChainUnaryInterceptor(UnaryServerErrors()))
+	defer stop()
+
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	e, ok := AsDomainError(err)
+	require.True(t, ok)
+	assert.Equal(t, "SERVICE_NOT_FOUND", e.Reason)
+	assert.Equal(t, "service is missing", e.Message)
+}
@@ -8,8 +8,10 @@ package app
 
 import (
 	"context"
+	"os"
 
 	"github.com/opentracing/opentracing-go"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/suryakencana007/mimir"
 	"github.com/suryakencana007/mimir/example/memorist/config"
@@ -22,13 +24,24 @@ func InitializeApplication() (func(), error) {
 		func(ctx context.Context) (mimir.AppRunner, func(), error) {
 			logger := mimir.With(mimir.Field("Headless", "listen and serve rpc"))
 			cfg := &config.Config{}
-			if err := mimir.Config(mimir.ConfigOpts{
+			opts := mimir.ConfigOpts{
 				Config:   cfg,
 				Filename: "app.config",
 				Paths:    []string{"./config"},
-			}, func(v *viper.Viper) error {
+			}
+			bindEnv := func(v *viper.Viper) error {
 				return v.BindEnv("db.dsn_main")
-			}); err != nil {
+			}
+			// --help shows the values of the configuration files and env
+			flags, err := mimir.ConfigFlagsWithOpts(opts, bindEnv, pflag.NewFlagSet(os.Args[0], pflag.ExitOnError))
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := flags.Parse(os.Args[1:]); err != nil {
+				return nil, nil, err
+			}
+			opts.Flags = flags
+			if err := mimir.Config(opts, bindEnv); err != nil {
 				return nil, nil, err
 			}
 
@@ -8,8 +8,11 @@ package app
 
 import (
 	"context"
+	"os"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/suryakencana007/mimir"
 	"github.com/suryakencana007/mimir/example/memorist/config"
@@ -22,13 +25,24 @@ func InitializeApplication() (func(), error) {
 		func(ctx context.Context) (mimir.AppRunner, func(), error) {
 			logger := mimir.With(mimir.Field("Headless", "listen and serve"))
 			cfg := &config.Config{}
-			if err := mimir.Config(mimir.ConfigOpts{
+			opts := mimir.ConfigOpts{
 				Config:   cfg,
 				Filename: "app.config",
 				Paths:    []string{"./config"},
-			}, func(v *viper.Viper) error {
+			}
+			bindEnv := func(v *viper.Viper) error {
 				return v.BindEnv("db.dsn_main")
-			}); err != nil {
+			}
+			// --help shows the values of the configuration files and env
+			flags, err := mimir.ConfigFlagsWithOpts(opts, bindEnv, pflag.NewFlagSet(os.Args[0], pflag.ExitOnError))
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := flags.Parse(os.Args[1:]); err != nil {
+				return nil, nil, err
+			}
+			opts.Flags = flags
+			if err := mimir.Config(opts, bindEnv); err != nil {
 				return nil, nil, err
 			}
 
@@ -40,6 +54,18 @@ func InitializeApplication() (func(), error) {
 
 			opentracing.SetGlobalTracer(tracer)
 
+			// booking rpc client, the pb clients are built on the connection
+			booking, err := mimir.DialRPC(ctx, cfg.GRPC.Target, mimir.DialRPCOpts{
+				Logger:  logger,
+				Tracer:  tracer,
+				Timeout: time.Duration(cfg.GRPC.Timeout) * time.Millisecond,
+				Retry:   &mimir.Retry{MaxAttempts: cfg.CB.Retry},
+			})
+			if err != nil {
+				cleanupTrace()
+				return nil, nil, err
+			}
+
 			// http router
 			router := http.Middleware(http.Options{
 				Config: cfg,
@@ -60,6 +86,7 @@ func InitializeApplication() (func(), error) {
 			}
 
 			return runner, func() {
+				_ = booking.Close()
 				cleanupTrace()
 				cleanup(ctx)
 			}, nil
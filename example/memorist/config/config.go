@@ -13,7 +13,7 @@ type Config struct {
 	App struct {
 		Name         string         `mapstructure:"name"`
 		Version      string         `mapstructure:"version"`
-		Port         int            `mapstructure:"port"`
+		Port         int            `mapstructure:"port" validate:"port" description:"http server port"`
 		ReadTimeout  int            `mapstructure:"read_timeout"`
 		WriteTimeout int            `mapstructure:"write_timeout"`
 		Timezone     string         `mapstructure:"timezone"`
@@ -34,12 +34,15 @@ type Config struct {
 		Concurrent int `mapstructure:"max_concurrent"`
 	}
 	DB struct {
-		DsnMain           string `mapstructure:"dsn_main" toml:"dsn_main,omitempty"`
+		DsnMain           string `mapstructure:"dsn_main" toml:"dsn_main,omitempty" validate:"required" description:"main database dsn"`
 		MaxLifeTime       int    `mapstructure:"max_life_time"`
 		MaxIdleConnection int    `mapstructure:"max_idle_connection"`
 		MaxOpenConnection int    `mapstructure:"max_open_connection"`
 	}
 	GRPC struct {
-		Port int `mapstructure:"port"`
+		Port int `mapstructure:"port" validate:"port" description:"grpc server port"`
+		// Target of the booking service, e.g. dns:///booking:50017
+		Target  string `mapstructure:"target" description:"grpc booking target"`
+		Timeout int    `mapstructure:"timeout"` // milliseconds
 	}
 }
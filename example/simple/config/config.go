@@ -15,7 +15,7 @@ import (
 type Config struct {
 	App struct {
 		Name         string         `mapstructure:"name"`
-		Port         int            `mapstructure:"port"`
+		Port         int            `mapstructure:"port" validate:"port"`
 		ReadTimeout  int            `mapstructure:"read_timeout"`
 		WriteTimeout int            `mapstructure:"write_timeout"`
 		Timezone     string         `mapstructure:"timezone"`
@@ -30,7 +30,7 @@ type Config struct {
 		Concurrent int `mapstructure:"max_concurrent"`
 	}
 	DB struct {
-		DsnMain           string `mapstructure:"dsn_main" toml:"dsn_main,omitempty"`
+		DsnMain           string `mapstructure:"dsn_main" toml:"dsn_main,omitempty" validate:"required"`
 		MaxLifeTime       int    `mapstructure:"max_life_time"`
 		MaxIdleConnection int    `mapstructure:"max_idle_connection"`
 		MaxOpenConnection int    `mapstructure:"max_open_connection"`
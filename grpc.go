@@ -10,8 +10,12 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"time"
 
+	"github.com/opentracing/opentracing-go"
 	rpc "google.golang.org/grpc"
+	channelz "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/reflection"
 )
 
 func GRPCkommen() string {
@@ -35,22 +39,53 @@ type (
 		Logger Logging
 		Port   GRPCPort
 		Opts   []rpc.ServerOption
+		// GracePeriod bounds the drain, the servers run by Application
+		// drain within its own grace period.
+		GracePeriod time.Duration
+		// Tracer of the tracing interceptors, defaults to the global tracer.
+		Tracer opentracing.Tracer
+		// DisableInterceptors leaves out the default tracing, logging and
+		// recovery interceptors.
+		DisableInterceptors bool
+		// UnaryInterceptors and StreamInterceptors run after the default ones.
+		UnaryInterceptors  []rpc.UnaryServerInterceptor
+		StreamInterceptors []rpc.StreamServerInterceptor
+		// Readiness drives the grpc.health.v1 status, defaults to the
+		// readiness of the Application running the server.
+		Readiness ReadinessChecker
+		// HealthCheckers are the extra readiness checks of single services.
+		HealthCheckers map[string]ReadinessChecker
+		HealthInterval time.Duration
+		DisableHealth  bool
+		// Reflection and Channelz register the server reflection and
+		// channelz services, e.g. for grpcurl and grpcdebug.
+		Reflection bool
+		Channelz   bool
 	}
 )
 
 func RemoteCallProc(opts GRPCOpts) (GRPCRunFunc, func()) {
 	logger := opts.Logger
-	s := rpc.NewServer(opts.Opts...) // GRpc Server
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = DefaultGracePeriod
+	}
+	s, hc := newGRPCServer(opts) // GRpc Server
 	cleanup := func() {
 		logger.Info("I have to go...")
 		logger.Info("Stopping server gracefully")
+		if hc != nil {
+			hc.Shutdown()
+		}
 		if s != nil {
 			logger.Infof("Stop server at :%d", opts.Port)
-			s.GracefulStop()
+			drainCtx, cancel := context.WithTimeout(context.Background(), opts.GracePeriod)
+			defer cancel()
+			gracefulStop(drainCtx, s)
 		}
 	}
 	return func(ctx context.Context, callback GRPCCallback) error {
-		errChan := make(chan error)
+		errChan := make(chan error, 1)
+		serving := listening(ctx)
 		go func() {
 			n, err := net.Listen("tcp", fmt.Sprintf(":%v", opts.Port))
 			if err != nil {
@@ -59,10 +94,13 @@ func RemoteCallProc(opts GRPCOpts) (GRPCRunFunc, func()) {
 					logger.Field("error", err.Error()),
 				).Error("failed to listen:")
 				errChan <- err
+				return
 			}
 
 			if err := callback(s); err != nil {
+				_ = n.Close()
 				errChan <- err
+				return
 			}
 			// Description µ micro service
 			fmt.Println(
@@ -71,18 +109,104 @@ func RemoteCallProc(opts GRPCOpts) (GRPCRunFunc, func()) {
 					opts.Port,
 				))
 			logger.Info(fmt.Sprintf("Now serving at %v", s.GetServiceInfo()))
+			serving()
+			if hc != nil {
+				watchGRPCHealth(ctx, s, hc, opts)
+			}
 			errChan <- s.Serve(n)
 		}()
 
 		select {
 		case err := <-errChan:
+			if hc != nil {
+				hc.Shutdown()
+			}
 			return err
 		case <-ctx.Done():
+			if hc != nil {
+				hc.Shutdown()
+			}
 			if s != nil {
-				logger.Infof("Stop server at :%d", opts.Port)
-				s.Stop()
+				logger.Infof("Draining server at :%d", opts.Port)
+				drainCtx, cancel := drainContext(ctx, opts.GracePeriod)
+				defer cancel()
+				gracefulStop(drainCtx, s)
 			}
 			return fmt.Errorf("server interrupted through context")
 		}
 	}, cleanup
 }
+
+// newGRPCServer builds the server of opts with the health, reflection and
+// channelz services, the health is nil when it is disabled.
+func newGRPCServer(opts GRPCOpts) (*rpc.Server, *GRPCHealth) {
+	s := rpc.NewServer(serverOptions(opts)...)
+	var hc *GRPCHealth
+	if !opts.DisableHealth {
+		hc = NewGRPCHealth(opts.Readiness, opts.HealthCheckers)
+		hc.Register(s)
+	}
+	if opts.Reflection {
+		reflection.Register(s)
+	}
+	if opts.Channelz {
+		channelz.RegisterChannelzServiceToServer(s)
+	}
+	return s, hc
+}
+
+// watchGRPCHealth reports the services registered on s until ctx is done,
+// the readiness of the Application is used when opts has none.
+func watchGRPCHealth(ctx context.Context, s *rpc.Server, hc *GRPCHealth, opts GRPCOpts) {
+	if r := ReadinessFrom(ctx); opts.Readiness == nil && r != nil {
+		hc.SetChecker(r)
+	}
+	names := make([]string, 0, len(s.GetServiceInfo()))
+	for name := range s.GetServiceInfo() {
+		names = append(names, name)
+	}
+	hc.Update(names...)
+	go hc.Watch(ctx, opts.HealthInterval)
+}
+
+// serverOptions prepends the interceptor chain to the options of opts.
+func serverOptions(opts GRPCOpts) []rpc.ServerOption {
+	var (
+		unary  []rpc.UnaryServerInterceptor
+		stream []rpc.StreamServerInterceptor
+	)
+	if !opts.DisableInterceptors {
+		tracer := opts.Tracer
+		if tracer == nil {
+			tracer = opentracing.GlobalTracer()
+		}
+		unary, stream = ServerInterceptors(opts.Logger, tracer)
+	}
+	unary = append(unary, opts.UnaryInterceptors...)
+	stream = append(stream, opts.StreamInterceptors...)
+
+	options := make([]rpc.ServerOption, 0, len(opts.Opts)+2)
+	if len(unary) > 0 {
+		options = append(options, rpc.ChainUnaryInterceptor(unary...))
+	}
+	if len(stream) > 0 {
+		options = append(options, rpc.ChainStreamInterceptor(stream...))
+	}
+	return append(options, opts.Opts...)
+}
+
+// gracefulStop stops accepting new streams and waits for the in-flight ones
+// until the grace period is over, then closes the server.
+func gracefulStop(ctx context.Context, s *rpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
@@ -0,0 +1,324 @@
+/*  grpc_client.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 23:40
+ */
+
+package mimir
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	opExt "github.com/opentracing/opentracing-go/ext"
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultRPCBalancer = "round_robin"
+	// DefaultRPCKeepalive stays above the 5 minutes minimum ping interval
+	// the servers enforce by default, pinging more often gets the
+	// connection closed with too_many_pings.
+	DefaultRPCKeepalive        = 5 * time.Minute
+	DefaultRPCKeepaliveTimeout = 20 * time.Second
+
+	staticScheme = "mimir"
+)
+
+// DialRPCOpts configures DialRPC. The calls go through logging, tracing,
+// timeout and retry before UnaryInterceptors and StreamInterceptors.
+type DialRPCOpts struct {
+	Logger Logging
+	// Tracer of the tracing interceptors, defaults to the global tracer.
+	Tracer opentracing.Tracer
+	// Timeout is the deadline of the unary calls made without one,
+	// zero leaves them unbounded.
+	Timeout time.Duration
+	// Retry retries the unary calls failing with RetryableCodes.
+	Retry          *Retry
+	RetryableCodes []codes.Code
+	// Balancer is the load balancing policy, defaults to round_robin.
+	Balancer string
+	// ServiceConfig replaces the default service config, which only sets
+	// the Balancer. The retryPolicy of a service config is honoured by
+	// grpc-go with GRPC_GO_RETRY=on only, hence the Retry interceptor.
+	ServiceConfig string
+	// Addresses are resolved statically instead of the target, e.g. the
+	// pods of a headless service. Without them a "dns:///host:port"
+	// target balances over the DNS records.
+	Addresses []string
+	Keepalive keepalive.ClientParameters
+	// TransportCredentials secures the connection, nil is insecure.
+	TransportCredentials credentials.TransportCredentials
+	// DisableInterceptors leaves out the default logging, tracing, timeout
+	// and retry interceptors.
+	DisableInterceptors bool
+	UnaryInterceptors   []rpc.UnaryClientInterceptor
+	StreamInterceptors  []rpc.StreamClientInterceptor
+	Opts                []rpc.DialOption
+}
+
+// DialRPC dials target with the client interceptors, keepalive and load
+// balancing of opts.
+//
+//	conn, err := mimir.DialRPC(ctx, "dns:///booking:9000", mimir.DialRPCOpts{
+//		Logger:  logger,
+//		Timeout: 3 * time.Second,
+//		Retry:   &mimir.Retry{MaxAttempts: 3},
+//	})
+func DialRPC(ctx context.Context, target string, opts DialRPCOpts) (*rpc.ClientConn, error) {
+	if opts.Balancer == "" {
+		opts.Balancer = DefaultRPCBalancer
+	}
+	if opts.ServiceConfig == "" {
+		opts.ServiceConfig = fmt.Sprintf(`{"loadBalancingPolicy":%q}`, opts.Balancer)
+	}
+	if opts.Keepalive.Time <= 0 {
+		opts.Keepalive.Time = DefaultRPCKeepalive
+	}
+	if opts.Keepalive.Timeout <= 0 {
+		opts.Keepalive.Timeout = DefaultRPCKeepaliveTimeout
+	}
+
+	options := []rpc.DialOption{
+		rpc.WithDefaultServiceConfig(opts.ServiceConfig),
+		rpc.WithKeepaliveParams(opts.Keepalive),
+	}
+	if opts.TransportCredentials != nil {
+		options = append(options, rpc.WithTransportCredentials(opts.TransportCredentials))
+	} else {
+		options = append(options, rpc.WithInsecure())
+	}
+	if len(opts.Addresses) > 0 {
+		r := manual.NewBuilderWithScheme(staticScheme)
+		addrs := make([]resolver.Address, 0, len(opts.Addresses))
+		for _, addr := range opts.Addresses {
+			addrs = append(addrs, resolver.Address{Addr: addr})
+		}
+		r.InitialState(resolver.State{Addresses: addrs})
+		options = append(options, rpc.WithResolvers(r))
+		target = staticScheme + ":///" + target
+	}
+	options = append(options, dialOptions(opts)...)
+
+	return rpc.DialContext(ctx, target, options...)
+}
+
+// dialOptions prepends the interceptor chain to the options of opts.
+func dialOptions(opts DialRPCOpts) []rpc.DialOption {
+	var (
+		unary  []rpc.UnaryClientInterceptor
+		stream []rpc.StreamClientInterceptor
+	)
+	if !opts.DisableInterceptors {
+		tracer := opts.Tracer
+		if tracer == nil {
+			tracer = opentracing.GlobalTracer()
+		}
+		if opts.Logger != nil {
+			unary = append(unary, UnaryClientLogging(opts.Logger))
+			stream = append(stream, StreamClientLogging(opts.Logger))
+		}
+		unary = append(unary, UnaryClientTracing(tracer), UnaryClientTimeout(opts.Timeout))
+		stream = append(stream, StreamClientTracing(tracer))
+		if opts.Retry != nil {
+			unary = append(unary, UnaryClientRetry(*opts.Retry, opts.RetryableCodes...))
+		}
+	}
+	unary = append(unary, opts.UnaryInterceptors...)
+	stream = append(stream, opts.StreamInterceptors...)
+
+	options := make([]rpc.DialOption, 0, len(opts.Opts)+2)
+	if len(unary) > 0 {
+		options = append(options, rpc.WithChainUnaryInterceptor(unary...))
+	}
+	if len(stream) > 0 {
+		options = append(options, rpc.WithChainStreamInterceptor(stream...))
+	}
+	return append(options, opts.Opts...)
+}
+
+// UnaryClientTimeout bounds the calls without a deadline by timeout,
+// zero disables it.
+func UnaryClientTimeout(timeout time.Duration) rpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *rpc.ClientConn, invoker rpc.UnaryInvoker, opts ...rpc.CallOption) error {
+		if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryClientRetry retries the calls failing with one of the codes,
+// codes.Unavailable when none is given. A Retryable of the policy
+// replaces the codes.
+func UnaryClientRetry(retry Retry, retryable ...codes.Code) rpc.UnaryClientInterceptor {
+	if len(retryable) == 0 {
+		retryable = []codes.Code{codes.Unavailable}
+	}
+	if retry.Retryable == nil {
+		retry.Retryable = func(err error) bool {
+			code := status.Code(err)
+			for _, c := range retryable {
+				if c == code {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *rpc.ClientConn, invoker rpc.UnaryInvoker, opts ...rpc.CallOption) error {
+		return retry.Do(ctx, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+// UnaryClientLogging logs every call with its code and duration.
+func UnaryClientLogging(logger Logging) rpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *rpc.ClientConn, invoker rpc.UnaryInvoker, opts ...rpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logClientRPC(logger, cc.Target(), method, "unary", start, err)
+		return err
+	}
+}
+
+// StreamClientLogging logs every stream with its code and duration once
+// it is finished.
+func StreamClientLogging(logger Logging) rpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *rpc.StreamDesc, cc *rpc.ClientConn, method string, streamer rpc.Streamer, opts ...rpc.CallOption) (rpc.ClientStream, error) {
+		start := time.Now()
+		finish := func(err error) {
+			logClientRPC(logger, cc.Target(), method, "stream", start, err)
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			finish(err)
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, finish: finish}, nil
+	}
+}
+
+func logClientRPC(logger Logging, target, method, kind string, start time.Time, err error) {
+	duration := time.Since(start)
+	code := status.Code(err)
+	fields := []interface{}{
+		logger.Field("code", code.String()),
+		logger.Field("duration", int(duration/time.Millisecond)),
+		logger.Field("duration-fmt", duration.String()),
+		logger.Field("method", method),
+		logger.Field("kind", kind),
+		logger.Field("target", target),
+	}
+	switch code {
+	case codes.OK:
+		logger.Info("Completed call", fields...)
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented, codes.Unavailable:
+		logger.Warn("Failed call", append(fields, logger.Field("error", err.Error()))...)
+	default:
+		logger.Info("Completed call", append(fields, logger.Field("error", err.Error()))...)
+	}
+}
+
+// UnaryClientTracing starts a client span, child of the span of the context,
+// and injects it into the outgoing metadata for UnaryServerTracing.
+func UnaryClientTracing(tracer opentracing.Tracer) rpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *rpc.ClientConn, invoker rpc.UnaryInvoker, opts ...rpc.CallOption) error {
+		span, ctx := startClientSpan(ctx, tracer, method)
+		defer span.Finish()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		finishRPCSpan(span, err)
+		return err
+	}
+}
+
+// StreamClientTracing starts a client span finished with the stream.
+func StreamClientTracing(tracer opentracing.Tracer) rpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *rpc.StreamDesc, cc *rpc.ClientConn, method string, streamer rpc.Streamer, opts ...rpc.CallOption) (rpc.ClientStream, error) {
+		span, ctx := startClientSpan(ctx, tracer, method)
+		finish := func(err error) {
+			finishRPCSpan(span, err)
+			span.Finish()
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			finish(err)
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, finish: finish}, nil
+	}
+}
+
+func startClientSpan(ctx context.Context, tracer opentracing.Tracer, method string) (opentracing.Span, context.Context) {
+	var parent opentracing.SpanContext
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		parent = span.Context()
+	}
+	span := tracer.StartSpan(method, opentracing.ChildOf(parent), opExt.SpanKindRPCClient)
+	opExt.Component.Set(span, "gRPC")
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	if err := tracer.Inject(span.Context(), opentracing.TextMap, MetadataCarrier(md)); err != nil {
+		For(ctx).Warnf("tracing err %s", err)
+	}
+	ctx = metadata.NewOutgoingContext(ctx, md)
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
+
+// clientStream calls finish once, when the stream ends on an error or on
+// io.EOF.
+type clientStream struct {
+	rpc.ClientStream
+	finish func(error)
+	once   sync.Once
+}
+
+func (s *clientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	if err != nil {
+		s.done(err)
+	}
+	return md, err
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil && err != io.EOF {
+		s.done(err)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == io.EOF {
+		s.done(nil)
+	} else if err != nil {
+		s.done(err)
+	}
+	return err
+}
+
+func (s *clientStream) done(err error) {
+	s.once.Do(func() { s.finish(err) })
+}
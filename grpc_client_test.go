@@ -0,0 +1,146 @@
+/*  grpc_client_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 23:55
+ */
+
+package mimir
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufServers serves probeServer on a bufconn listener per address, the
+// returned dialer connects to them and hits counts the calls per address.
+// The servers trace the calls with tracer.
+func bufServers(tracer opentracing.Tracer, addrs []string, opts ...rpc.ServerOption) (dialer rpc.DialOption, hits map[string]*int32, stop func()) {
+	listeners := make(map[string]*bufconn.Listener, len(addrs))
+	servers := make([]*rpc.Server, 0, len(addrs))
+	hits = make(map[string]*int32, len(addrs))
+	for _, addr := range addrs {
+		n := new(int32)
+		count := func(ctx context.Context, req interface{}, info *rpc.UnaryServerInfo, handler rpc.UnaryHandler) (interface{}, error) {
+			atomic.AddInt32(n, 1)
+			return handler(ctx, req)
+		}
+		lis := bufconn.Listen(1 << 20)
+		s := rpc.NewServer(append([]rpc.ServerOption{rpc.ChainUnaryInterceptor(UnaryServerTracing(tracer), count)}, opts...)...)
+		healthpb.RegisterHealthServer(s, probeServer{})
+		go func() { _ = s.Serve(lis) }()
+		listeners[addr] = lis
+		hits[addr] = n
+		servers = append(servers, s)
+	}
+	dialer = rpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		return listeners[addr].Dial()
+	})
+	return dialer, hits, func() {
+		for _, s := range servers {
+			s.Stop()
+		}
+	}
+}
+
+func TestDialRPCRoundRobin(t *testing.T) {
+	addrs := []string{"booking-0", "booking-1"}
+	dialer, hits, stop := bufServers(mocktracer.New(), addrs)
+	defer stop()
+
+	conn, err := DialRPC(context.Background(), "booking", DialRPCOpts{
+		Addresses: addrs,
+		Opts:      []rpc.DialOption{dialer},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	assert.Eventually(t, func() bool {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		return atomic.LoadInt32(hits["booking-0"]) > 0 && atomic.LoadInt32(hits["booking-1"]) > 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestDialRPCTracing(t *testing.T) {
+	logger, spy := newZap(t)
+	tracer := mocktracer.New()
+	dialer, _, stop := bufServers(tracer, []string{"booking"})
+	defer stop()
+
+	conn, err := DialRPC(context.Background(), "booking", DialRPCOpts{
+		Logger:    logger,
+		Tracer:    tracer,
+		Addresses: []string{"booking"},
+		Opts:      []rpc.DialOption{dialer},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	parent := tracer.StartSpan("handler")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 2)
+	server, client := spans[0], spans[1]
+	assert.Equal(t, "/grpc.health.v1.Health/Check", client.OperationName)
+	assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, client.ParentID)
+	assert.Equal(t, client.SpanContext.SpanID, server.ParentID)
+	assert.Equal(t, "OK", client.Tag("grpc.code"))
+	spy.AssertMessages("Completed call")
+}
+
+func TestDialRPCTimeoutAndRetry(t *testing.T) {
+	var (
+		calls    int32
+		deadline int32
+	)
+	unavailable := func(ctx context.Context, req interface{}, info *rpc.UnaryServerInfo, handler rpc.UnaryHandler) (interface{}, error) {
+		if _, ok := ctx.Deadline(); ok {
+			atomic.StoreInt32(&deadline, 1)
+		}
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return nil, status.Error(codes.Unavailable, "warming up")
+		}
+		return handler(ctx, req)
+	}
+	dialer, _, stop := bufServers(mocktracer.New(), []string{"booking"}, rpc.ChainUnaryInterceptor(unavailable, UnaryServerErrors()))
+	defer stop()
+
+	conn, err := DialRPC(context.Background(), "booking", DialRPCOpts{
+		Timeout:   time.Minute,
+		Retry:     &Retry{MaxAttempts: 3, Sleep: noSleep},
+		Addresses: []string{"booking"},
+		Opts:      []rpc.DialOption{dialer},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&deadline))
+
+	// the other codes are not retried
+	atomic.StoreInt32(&calls, 2)
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
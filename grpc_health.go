@@ -0,0 +1,120 @@
+/*  grpc_health.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 22:00
+ */
+
+package mimir
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const DefaultHealthInterval = time.Second
+
+// GRPCHealth serves grpc.health.v1 with the status of every service driven
+// by a ReadinessChecker, the overall status is the one of the "" service.
+type GRPCHealth struct {
+	server   *health.Server
+	mu       sync.Mutex
+	checker  ReadinessChecker
+	services map[string]ReadinessChecker
+	names    []string
+	shutdown chan struct{}
+	once     sync.Once
+}
+
+// NewGRPCHealth reports SERVING while checker is ready, nil is always
+// ready, and a service of services while its own checker is ready too.
+func NewGRPCHealth(checker ReadinessChecker, services map[string]ReadinessChecker) *GRPCHealth {
+	return &GRPCHealth{
+		server:   health.NewServer(),
+		checker:  checker,
+		services: services,
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Register adds the health service to s.
+func (h *GRPCHealth) Register(s *rpc.Server) {
+	healthpb.RegisterHealthServer(s, h.server)
+}
+
+// SetChecker replaces the overall readiness checker.
+func (h *GRPCHealth) SetChecker(checker ReadinessChecker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checker = checker
+}
+
+// Update sets the status of the "" service and of the given services.
+func (h *GRPCHealth) Update(names ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, name := range names {
+		if !h.known(name) {
+			h.names = append(h.names, name)
+		}
+	}
+
+	ready := h.checker == nil || h.checker.IsReady()
+	h.server.SetServingStatus("", servingStatus(ready))
+	for _, name := range h.names {
+		service := ready
+		if c, ok := h.services[name]; ok && c != nil {
+			service = service && c.IsReady()
+		}
+		h.server.SetServingStatus(name, servingStatus(service))
+	}
+}
+
+func (h *GRPCHealth) known(name string) bool {
+	for _, n := range h.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch updates the statuses every interval until ctx is done or
+// the health is shut down.
+func (h *GRPCHealth) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultHealthInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.shutdown:
+			return
+		case <-ticker.C:
+			h.Update()
+		}
+	}
+}
+
+// Shutdown sets every service NOT_SERVING for good, load balancers stop
+// routing to the server before it drains.
+func (h *GRPCHealth) Shutdown() {
+	h.once.Do(func() {
+		close(h.shutdown)
+		h.server.Shutdown()
+	})
+}
+
+func servingStatus(ready bool) healthpb.HealthCheckResponse_ServingStatus {
+	if ready {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
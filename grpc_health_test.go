@@ -0,0 +1,128 @@
+/*  grpc_health_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 22:20
+ */
+
+package mimir
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func healthStatus(t *testing.T, client healthpb.HealthClient, service string) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.Status
+}
+
+func TestGRPCHealth(t *testing.T) {
+	ready, payment := NewReadiness(), NewReadiness()
+	ready.SetReady(true)
+	payment.SetReady(true)
+	hc := NewGRPCHealth(ready, map[string]ReadinessChecker{"payment": payment})
+
+	lis := bufconn.Listen(1 << 20)
+	s := rpc.NewServer()
+	hc.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	conn, err := rpc.Dial("bufnet",
+		rpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		rpc.WithInsecure(),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	hc.Update("payment", "booking")
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, client, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, client, "payment"))
+
+	payment.SetReady(false)
+	hc.Update()
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, client, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, client, "booking"))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, client, "payment"))
+
+	ready.SetReady(false)
+	hc.Update()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, client, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, client, "booking"))
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// a watcher sees the shutdown, later updates are ignored
+	ready.SetReady(true)
+	payment.SetReady(true)
+	hc.Update()
+	watch, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{Service: "payment"})
+	require.NoError(t, err)
+	resp, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	hc.Shutdown()
+	resp, err = watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+	hc.Update()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, client, ""))
+}
+
+func TestRemoteCallProcHealth(t *testing.T) {
+	logger, _ := newZap(t)
+	port, err := findOpenPort()
+	require.NoError(t, err)
+
+	run, _ := RemoteCallProc(GRPCOpts{
+		Logger:         logger,
+		Port:           GRPCPort(port),
+		GracePeriod:    time.Second,
+		HealthInterval: 10 * time.Millisecond,
+		Reflection:     true,
+		Channelz:       true,
+	})
+	// the readiness of the Application drives the health
+	readiness := NewReadiness()
+	readiness.SetReady(true)
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), CtxReadiness, readiness))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = run(ctx, func(*rpc.Server) error { return nil })
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	require.NoError(t, waitForPort(port))
+
+	conn, err := rpc.Dial(fmt.Sprintf("localhost:%d", port), rpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, client, ""))
+	// every registered service has a status
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, client, "grpc.reflection.v1alpha.ServerReflection"))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, client, "grpc.channelz.v1.Channelz"))
+
+	readiness.SetReady(false)
+	assert.Eventually(t, func() bool {
+		return healthStatus(t, client, "") == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 10*time.Millisecond)
+}
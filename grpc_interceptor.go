@@ -0,0 +1,208 @@
+/*  grpc_interceptor.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 21:10
+ */
+
+package mimir
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	opExt "github.com/opentracing/opentracing-go/ext"
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataCarrier adapts gRPC metadata to the opentracing TextMap carriers.
+type MetadataCarrier metadata.MD
+
+func (c MetadataCarrier) Set(key, val string) {
+	key = strings.ToLower(key)
+	metadata.MD(c)[key] = append(metadata.MD(c)[key], val)
+}
+
+func (c MetadataCarrier) ForeachKey(handler func(key, val string) error) error {
+	for key, values := range c {
+		for _, value := range values {
+			if err := handler(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// serverStream overrides the context of a stream.
+type serverStream struct {
+	rpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// ServerInterceptors returns the default unary and stream interceptors of
+// RemoteCallProc: tracing, then access logging, then error mapping, then
+// panic recovery.
+func ServerInterceptors(logger Logging, tracer opentracing.Tracer) ([]rpc.UnaryServerInterceptor, []rpc.StreamServerInterceptor) {
+	return []rpc.UnaryServerInterceptor{
+		UnaryServerTracing(tracer),
+		UnaryServerLogging(logger),
+		UnaryServerErrors(),
+		UnaryServerRecovery(logger),
+	}, []rpc.StreamServerInterceptor{
+		StreamServerTracing(tracer),
+		StreamServerLogging(logger),
+		StreamServerErrors(),
+		StreamServerRecovery(logger),
+	}
+}
+
+// UnaryServerErrors returns the errors of the handlers as the status of
+// their DomainError, see DomainErrorFrom.
+func UnaryServerErrors() rpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *rpc.UnaryServerInfo, handler rpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, DomainErrorFrom(err).GRPCStatus().Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerErrors returns the errors of the handlers as the status of
+// their DomainError, see DomainErrorFrom.
+func StreamServerErrors() rpc.StreamServerInterceptor {
+	return func(srv interface{}, ss rpc.ServerStream, info *rpc.StreamServerInfo, handler rpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return DomainErrorFrom(err).GRPCStatus().Err()
+		}
+		return nil
+	}
+}
+
+// UnaryServerRecovery turns the panics of the handlers into codes.Internal.
+func UnaryServerRecovery(logger Logging) rpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *rpc.UnaryServerInfo, handler rpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverRPC(logger, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecovery turns the panics of the handlers into codes.Internal.
+func StreamServerRecovery(logger Logging) rpc.StreamServerInterceptor {
+	return func(srv interface{}, ss rpc.ServerStream, info *rpc.StreamServerInfo, handler rpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverRPC(logger, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recoverRPC(logger Logging, method string, r interface{}) error {
+	logger.Errorf("Internal server error handled in %s: %v\n%s", method, r, debug.Stack())
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// UnaryServerLogging logs every call with its code and duration.
+func UnaryServerLogging(logger Logging) rpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *rpc.UnaryServerInfo, handler rpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logRPC(ctx, logger, info.FullMethod, "unary", start, err)
+		return resp, err
+	}
+}
+
+// StreamServerLogging logs every stream with its code and duration.
+func StreamServerLogging(logger Logging) rpc.StreamServerInterceptor {
+	return func(srv interface{}, ss rpc.ServerStream, info *rpc.StreamServerInfo, handler rpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logRPC(ss.Context(), logger, info.FullMethod, "stream", start, err)
+		return err
+	}
+}
+
+func logRPC(ctx context.Context, logger Logging, method, kind string, start time.Time, err error) {
+	duration := time.Since(start)
+	code := status.Code(err)
+	var remote string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remote = p.Addr.String()
+	}
+	fields := []interface{}{
+		logger.Field("code", code.String()),
+		logger.Field("duration", int(duration/time.Millisecond)),
+		logger.Field("duration-fmt", duration.String()),
+		logger.Field("method", method),
+		logger.Field("kind", kind),
+		logger.Field("remote-addr", remote),
+	}
+	switch code {
+	case codes.OK:
+		logger.Info("Completed handling call", fields...)
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		logger.Warn("Failed handling call", append(fields, logger.Field("error", err.Error()))...)
+	default:
+		logger.Info("Completed handling call", append(fields, logger.Field("error", err.Error()))...)
+	}
+}
+
+// UnaryServerTracing starts a server span, child of the span of the incoming
+// metadata, and puts it into the context so For(ctx) logs to it.
+func UnaryServerTracing(tracer opentracing.Tracer) rpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *rpc.UnaryServerInfo, handler rpc.UnaryHandler) (interface{}, error) {
+		span, ctx := startServerSpan(ctx, tracer, info.FullMethod)
+		defer span.Finish()
+		resp, err := handler(ctx, req)
+		finishRPCSpan(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerTracing starts a server span for the whole stream.
+func StreamServerTracing(tracer opentracing.Tracer) rpc.StreamServerInterceptor {
+	return func(srv interface{}, ss rpc.ServerStream, info *rpc.StreamServerInfo, handler rpc.StreamHandler) error {
+		span, ctx := startServerSpan(ss.Context(), tracer, info.FullMethod)
+		defer span.Finish()
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		finishRPCSpan(span, err)
+		return err
+	}
+}
+
+func startServerSpan(ctx context.Context, tracer opentracing.Tracer, method string) (opentracing.Span, context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	parent, err := tracer.Extract(opentracing.TextMap, MetadataCarrier(md))
+	if err != nil && err != opentracing.ErrSpanContextNotFound {
+		For(ctx).Warnf("tracing err %s", err)
+	}
+	span := tracer.StartSpan(method, opExt.RPCServerOption(parent), opExt.SpanKindRPCServer)
+	opExt.Component.Set(span, "gRPC")
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
+
+func finishRPCSpan(span opentracing.Span, err error) {
+	code := status.Code(err)
+	span.SetTag("grpc.code", code.String())
+	if err != nil {
+		opExt.Error.Set(span, true)
+		span.LogKV("event", "error", "message", err.Error())
+	}
+}
@@ -0,0 +1,158 @@
+/*  grpc_interceptor_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 21:30
+ */
+
+package mimir
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// probeServer panics on the "panic" service, fails with a wrapped
+// DomainError on the "missing" service and reports whether the handler
+// context carries a span.
+type probeServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (probeServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	switch req.Service {
+	case "panic":
+		panic("boom")
+	case "missing":
+		return nil, fmt.Errorf("lookup: %w", NewDomainError(codes.NotFound, "SERVICE_NOT_FOUND", "service is missing"))
+	}
+	if opentracing.SpanFromContext(ctx) == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no span")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (probeServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if req.Service == "panic" {
+		panic("boom")
+	}
+	if opentracing.SpanFromContext(stream.Context()) == nil {
+		return status.Error(codes.FailedPrecondition, "no span")
+	}
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+// newBufServer serves the probe server in memory and returns a connection to it.
+func newBufServer(t *testing.T, opts ...rpc.ServerOption) (*rpc.ClientConn, func()) {
+	lis := bufconn.Listen(1 << 20)
+	s := rpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(s, probeServer{})
+	go func() { _ = s.Serve(lis) }()
+
+	conn, err := rpc.DialContext(context.Background(), "bufnet",
+		rpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		rpc.WithInsecure(),
+	)
+	require.NoError(t, err)
+	return conn, func() {
+		_ = conn.Close()
+		s.GracefulStop()
+	}
+}
+
+func TestServerInterceptors(t *testing.T) {
+	logger, spy := newZap(t)
+	tracer := mocktracer.New()
+	unary, stream := ServerInterceptors(logger, tracer)
+	conn, stop := newBufServer(t, rpc.ChainUnaryInterceptor(unary...), rpc.ChainStreamInterceptor(stream...))
+	client := healthpb.NewHealthClient(conn)
+
+	parent := tracer.StartSpan("client")
+	md := metadata.MD{}
+	require.NoError(t, tracer.Inject(parent.Context(), opentracing.TextMap, MetadataCarrier(md)))
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "panic"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	watch, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = watch.Recv()
+	require.NoError(t, err)
+
+	watch, err = client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "panic"})
+	require.NoError(t, err)
+	_, err = watch.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	// the server is stopped so every span and log line is written
+	stop()
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 4)
+	parentID := parent.Context().(mocktracer.MockSpanContext).SpanID
+	for _, span := range spans {
+		assert.Equal(t, parentID, span.ParentID)
+		assert.Equal(t, "server", fmt.Sprint(span.Tag("span.kind")))
+	}
+	assert.Equal(t, "/grpc.health.v1.Health/Check", spans[0].OperationName)
+	assert.Equal(t, "OK", spans[0].Tag("grpc.code"))
+	assert.Equal(t, "Internal", spans[1].Tag("grpc.code"))
+	assert.Equal(t, true, spans[1].Tag("error"))
+	assert.Equal(t, "/grpc.health.v1.Health/Watch", spans[2].OperationName)
+
+	spy.AssertMessages("Completed handling call")
+	assert.Contains(t, fmt.Sprint(spy.Messages), "Failed handling call")
+	assert.Contains(t, fmt.Sprint(spy.Messages), "Internal server error handled in /grpc.health.v1.Health/Check: boom")
+}
+
+func TestRemoteCallProcInterceptors(t *testing.T) {
+	logger, _ := newZap(t)
+	port, err := findOpenPort()
+	require.NoError(t, err)
+
+	// the probe server takes the place of the health service
+	run, _ := RemoteCallProc(GRPCOpts{Logger: logger, Port: GRPCPort(port), GracePeriod: time.Second, DisableHealth: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = run(ctx, func(s *rpc.Server) error {
+			healthpb.RegisterHealthServer(s, probeServer{})
+			return nil
+		})
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	require.NoError(t, waitForPort(port))
+
+	conn, err := rpc.Dial(fmt.Sprintf("localhost:%d", port), rpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	// the panic is recovered and the handler context has a span
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "panic"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,562 @@
+/*  hasher.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 17:00
+ */
+
+package mimir
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	DefaultSaltLength = 16
+	DefaultKeyLength  = 32
+
+	DefaultArgon2Memory      = 64 * 1024
+	DefaultArgon2Iterations  = 3
+	DefaultArgon2Parallelism = 2
+
+	DefaultScryptLogN = 15
+	DefaultScryptR    = 8
+	DefaultScryptP    = 1
+)
+
+// the bounds of the stored hashes, a shorter key or salt verifies too
+// many passwords and the argon2id costs are capped so that a stored hash
+// cannot exhaust the memory or the CPU.
+const (
+	minSaltLength = 8
+	minKeyLength  = 16
+
+	maxArgon2Memory      = 4 * 1024 * 1024
+	maxArgon2Iterations  = 64
+	maxArgon2Parallelism = 64
+)
+
+// PasswordHasher hashes passwords into a modular crypt string,
+// e.g. $argon2id$v=19$m=65536,t=3,p=2$salt$hash.
+type PasswordHasher interface {
+	// Identifiers are the identifiers of the hash strings the hasher
+	// verifies, "argon2id" for $argon2id$...
+	Identifiers() []string
+	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	// NeedsRehash reports whether the hash was made with weaker
+	// parameters than the ones of the hasher.
+	NeedsRehash(hash string) bool
+}
+
+// PasswordHashers is a registry of hashers, new passwords are hashed with
+// the preferred one and every registered algorithm is verified.
+type PasswordHashers struct {
+	mu        sync.RWMutex
+	preferred PasswordHasher
+	hashers   map[string]PasswordHasher
+}
+
+func NewPasswordHashers(preferred PasswordHasher, others ...PasswordHasher) *PasswordHashers {
+	h := &PasswordHashers{preferred: preferred, hashers: make(map[string]PasswordHasher)}
+	for _, hasher := range others {
+		h.Register(hasher)
+	}
+	h.Register(preferred)
+	return h
+}
+
+// DefaultPasswordHashers prefers argon2id and verifies bcrypt, scrypt and pbkdf2.
+var DefaultPasswordHashers = NewPasswordHashers(
+	&Argon2idHasher{},
+	&BcryptHasher{},
+	&ScryptHasher{},
+	&PBKDF2Hasher{},
+)
+
+// Register adds a hasher, it replaces the hasher of the same identifiers.
+func (h *PasswordHashers) Register(hasher PasswordHasher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, id := range hasher.Identifiers() {
+		h.hashers[id] = hasher
+	}
+}
+
+// SetPreferred changes the hasher of new passwords.
+func (h *PasswordHashers) SetPreferred(hasher PasswordHasher) {
+	h.Register(hasher)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.preferred = hasher
+}
+
+func (h *PasswordHashers) Hash(password string) (string, error) {
+	h.mu.RLock()
+	preferred := h.preferred
+	h.mu.RUnlock()
+	return preferred.Hash(password)
+}
+
+// Verify checks the password with the hasher of the hash identifier.
+func (h *PasswordHashers) Verify(hash, password string) (bool, error) {
+	hasher, err := h.lookup(hash)
+	if err != nil {
+		return false, err
+	}
+	return hasher.Verify(hash, password)
+}
+
+// NeedsRehash reports whether the hash was not made by the preferred
+// hasher or with weaker parameters.
+func (h *PasswordHashers) NeedsRehash(hash string) bool {
+	hasher, err := h.lookup(hash)
+	if err != nil {
+		return true
+	}
+	h.mu.RLock()
+	preferred := h.preferred
+	h.mu.RUnlock()
+	return hasher != preferred || hasher.NeedsRehash(hash)
+}
+
+func (h *PasswordHashers) lookup(hash string) (PasswordHasher, error) {
+	id := hashIdentifier(hash)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	hasher, ok := h.hashers[id]
+	if !ok {
+		return nil, fmt.Errorf("unsupported password hash %q", id)
+	}
+	return hasher, nil
+}
+
+// NeedsRehash reports whether the hash should be replaced on the next login.
+func NeedsRehash(hash string) bool {
+	return DefaultPasswordHashers.NeedsRehash(hash)
+}
+
+func hashIdentifier(hash string) string {
+	if !strings.HasPrefix(hash, "$") {
+		return ""
+	}
+	return strings.SplitN(hash[1:], "$", 2)[0]
+}
+
+// phcParams parses the k=v,k=v parameters of a PHC string.
+func phcParams(s string) (map[string]int, error) {
+	params := make(map[string]int)
+	for _, kv := range strings.Split(s, ",") {
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("invalid hash parameter %q", kv)
+		}
+		v, err := strconv.Atoi(pair[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash parameter %q", kv)
+		}
+		params[pair[0]] = v
+	}
+	return params, nil
+}
+
+func randomSalt(n int) ([]byte, error) {
+	salt := make([]byte, n)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// Argon2idHasher hashes in the PHC format, Memory is in KiB.
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  int
+	KeyLength   uint32
+}
+
+func (a *Argon2idHasher) params() Argon2idHasher {
+	p := *a
+	if p.Memory == 0 {
+		p.Memory = DefaultArgon2Memory
+	}
+	if p.Iterations == 0 {
+		p.Iterations = DefaultArgon2Iterations
+	}
+	if p.Parallelism == 0 {
+		p.Parallelism = DefaultArgon2Parallelism
+	}
+	if p.SaltLength <= 0 {
+		p.SaltLength = DefaultSaltLength
+	}
+	if p.KeyLength == 0 {
+		p.KeyLength = DefaultKeyLength
+	}
+	return p
+}
+
+func (a *Argon2idHasher) Identifiers() []string {
+	return []string{"argon2id"}
+}
+
+func (a *Argon2idHasher) Hash(password string) (string, error) {
+	p := a.params()
+	salt, err := randomSalt(p.SaltLength)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+type argon2Hash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt, key   []byte
+}
+
+func parseArgon2id(hash string) (*argon2Hash, error) {
+	// $argon2id$v=19$m=65536,t=3,p=2$salt$hash
+	fields := strings.Split(hash, "$")
+	if len(fields) != 6 || fields[1] != "argon2id" {
+		return nil, fmt.Errorf("invalid argon2id hash format")
+	}
+	if fields[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return nil, fmt.Errorf("unsupported argon2id version %s", fields[2])
+	}
+	params, err := phcParams(fields[3])
+	if err != nil {
+		return nil, err
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return nil, fmt.Errorf("invalid argon2id salt")
+	}
+	key, err := base64.RawStdEncoding.DecodeString(fields[5])
+	if err != nil {
+		return nil, fmt.Errorf("invalid argon2id hash")
+	}
+	if len(salt) < minSaltLength {
+		return nil, fmt.Errorf("invalid argon2id salt")
+	}
+	if len(key) < minKeyLength {
+		return nil, fmt.Errorf("invalid argon2id hash")
+	}
+	if params["m"] <= 0 || params["m"] > maxArgon2Memory ||
+		params["t"] <= 0 || params["t"] > maxArgon2Iterations ||
+		params["p"] <= 0 || params["p"] > maxArgon2Parallelism {
+		return nil, fmt.Errorf("invalid argon2id parameters")
+	}
+	return &argon2Hash{
+		memory:      uint32(params["m"]),
+		iterations:  uint32(params["t"]),
+		parallelism: uint8(params["p"]),
+		salt:        salt,
+		key:         key,
+	}, nil
+}
+
+func (a *Argon2idHasher) Verify(hash, password string) (bool, error) {
+	h, err := parseArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), h.salt, h.iterations, h.memory, h.parallelism, uint32(len(h.key)))
+	return subtle.ConstantTimeCompare(key, h.key) == 1, nil
+}
+
+func (a *Argon2idHasher) NeedsRehash(hash string) bool {
+	h, err := parseArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	p := a.params()
+	return h.memory < p.Memory || h.iterations < p.Iterations ||
+		h.parallelism < p.Parallelism || uint32(len(h.key)) < p.KeyLength
+}
+
+// BcryptHasher hashes with bcrypt, Cost defaults to bcrypt.DefaultCost.
+type BcryptHasher struct {
+	Cost int
+}
+
+func (b *BcryptHasher) cost() int {
+	if b.Cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return b.Cost
+}
+
+func (b *BcryptHasher) Identifiers() []string {
+	return []string{"2a", "2b", "2y"}
+}
+
+func (b *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost())
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (b *BcryptHasher) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	switch err {
+	case nil:
+		return true, nil
+	case bcrypt.ErrMismatchedHashAndPassword:
+		return false, nil
+	}
+	return false, err
+}
+
+func (b *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < b.cost()
+}
+
+// ScryptHasher hashes in the passlib format $scrypt$ln=15,r=8,p=1$salt$hash,
+// the cost is N = 2^LogN.
+type ScryptHasher struct {
+	LogN       int
+	R          int
+	P          int
+	SaltLength int
+	KeyLength  int
+}
+
+func (s *ScryptHasher) params() ScryptHasher {
+	p := *s
+	if p.LogN <= 0 {
+		p.LogN = DefaultScryptLogN
+	}
+	if p.R <= 0 {
+		p.R = DefaultScryptR
+	}
+	if p.P <= 0 {
+		p.P = DefaultScryptP
+	}
+	if p.SaltLength <= 0 {
+		p.SaltLength = DefaultSaltLength
+	}
+	if p.KeyLength <= 0 {
+		p.KeyLength = DefaultKeyLength
+	}
+	return p
+}
+
+func (s *ScryptHasher) Identifiers() []string {
+	return []string{"scrypt"}
+}
+
+func (s *ScryptHasher) Hash(password string) (string, error) {
+	p := s.params()
+	salt, err := randomSalt(p.SaltLength)
+	if err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<uint(p.LogN), p.R, p.P, p.KeyLength)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(
+		"$scrypt$ln=%d,r=%d,p=%d$%s$%s",
+		p.LogN, p.R, p.P,
+		PassLibBase64Encode(salt),
+		PassLibBase64Encode(key),
+	), nil
+}
+
+type scryptHash struct {
+	logN, r, p int
+	salt, key  []byte
+}
+
+func parseScrypt(hash string) (*scryptHash, error) {
+	fields := strings.Split(hash, "$")
+	if len(fields) != 5 || fields[1] != "scrypt" {
+		return nil, fmt.Errorf("invalid scrypt hash format")
+	}
+	params, err := phcParams(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	salt, err := PassLibBase64Decode(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid scrypt salt")
+	}
+	if len(salt) < minSaltLength {
+		return nil, fmt.Errorf("invalid scrypt salt")
+	}
+	key, err := PassLibBase64Decode(fields[4])
+	if err != nil || len(key) < minKeyLength {
+		return nil, fmt.Errorf("invalid scrypt hash")
+	}
+	if params["ln"] <= 0 || params["ln"] > 31 || params["r"] <= 0 || params["p"] <= 0 {
+		return nil, fmt.Errorf("invalid scrypt parameters")
+	}
+	return &scryptHash{logN: params["ln"], r: params["r"], p: params["p"], salt: salt, key: key}, nil
+}
+
+func (s *ScryptHasher) Verify(hash, password string) (bool, error) {
+	h, err := parseScrypt(hash)
+	if err != nil {
+		return false, err
+	}
+	key, err := scrypt.Key([]byte(password), h.salt, 1<<uint(h.logN), h.r, h.p, len(h.key))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(key, h.key) == 1, nil
+}
+
+func (s *ScryptHasher) NeedsRehash(hash string) bool {
+	h, err := parseScrypt(hash)
+	if err != nil {
+		return true
+	}
+	p := s.params()
+	return h.logN < p.LogN || h.r < p.R || h.p < p.P || len(h.key) < p.KeyLength
+}
+
+// PBKDF2Hasher hashes in the passlib format $pbkdf2-sha512$rounds$salt$hash,
+// $pbkdf2$ for sha1. Digest is sha1, sha256 or sha512, the default, and
+// Rounds defaults to the recommended rounds of the digest.
+type PBKDF2Hasher struct {
+	Digest     string
+	Rounds     int
+	SaltLength int
+}
+
+func (p *PBKDF2Hasher) digest() string {
+	if p.Digest == "" {
+		return "sha512"
+	}
+	return p.Digest
+}
+
+func (p *PBKDF2Hasher) rounds() int {
+	if p.Rounds > 0 {
+		return p.Rounds
+	}
+	switch p.digest() {
+	case "sha1":
+		return RecommendedRoundsSHA1
+	case "sha256":
+		return RecommendedRoundsSHA256
+	}
+	return RecommendedRoundsSHA512
+}
+
+func (p *PBKDF2Hasher) Identifiers() []string {
+	return []string{"pbkdf2", "pbkdf2-sha256", "pbkdf2-sha512"}
+}
+
+// pbkdf2Identifier is the passlib identifier of the digest.
+func pbkdf2Identifier(digest string) string {
+	if digest == "sha1" {
+		return "pbkdf2"
+	}
+	return "pbkdf2-" + digest
+}
+
+func (p *PBKDF2Hasher) Hash(password string) (string, error) {
+	n := p.SaltLength
+	if n <= 0 {
+		n = DefaultSaltLength
+	}
+	salt, err := randomSalt(n)
+	if err != nil {
+		return "", err
+	}
+	keyLen, hashFunc, err := pbkdf2Digest(p.digest())
+	if err != nil {
+		return "", err
+	}
+	key := pbkdf2.Key([]byte(password), salt, p.rounds(), keyLen, hashFunc)
+	return fmt.Sprintf(
+		"$%s$%d$%s$%s",
+		pbkdf2Identifier(p.digest()), p.rounds(),
+		PassLibBase64Encode(salt),
+		PassLibBase64Encode(key),
+	), nil
+}
+
+func (p *PBKDF2Hasher) Verify(hashpassword, password string) (bool, error) {
+	// five fields expected: $pbkdf2-digest$rounds$salt$checksum
+	fields := strings.Split(hashpassword, "$")
+	if len(fields) != 5 {
+		return false, fmt.Errorf("invalid hashPass format")
+	}
+	// extract digest, passlib names the sha1 variant $pbkdf2$
+	digest := "sha1"
+	if fields[1] != "pbkdf2" {
+		hdr := strings.Split(fields[1], "-")
+		if len(hdr) != 2 {
+			return false, fmt.Errorf("invalid digest")
+		}
+		digest = hdr[1]
+	}
+	keyLen, hashFunc, err := pbkdf2Digest(digest)
+	if err != nil {
+		return false, err
+	}
+	// get remaining fields
+	rounds, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return false, fmt.Errorf("invalid hashPass roound")
+	}
+	salt, err := PassLibBase64Decode(fields[3])
+	if err != nil {
+		return false, fmt.Errorf("invalid hashPass salt")
+	}
+	checksum, err := PassLibBase64Decode(fields[4])
+	if err != nil || len(checksum) != keyLen {
+		return false, fmt.Errorf("invalid hashPass checksum")
+	}
+	key := pbkdf2.Key([]byte(password), salt, rounds, keyLen, hashFunc)
+	return subtle.ConstantTimeCompare(checksum, key) == 1, nil
+}
+
+func (p *PBKDF2Hasher) NeedsRehash(hash string) bool {
+	fields := strings.Split(hash, "$")
+	if len(fields) != 5 || fields[1] != pbkdf2Identifier(p.digest()) {
+		return true
+	}
+	rounds, err := strconv.Atoi(fields[2])
+	return err != nil || rounds < p.rounds()
+}
+
+func pbkdf2Digest(digest string) (int, func() hash.Hash, error) {
+	switch digest {
+	case "sha1":
+		return sha1.Size, sha1.New, nil
+	case "sha256":
+		return sha256.Size, sha256.New, nil
+	case "sha512":
+		return sha512.Size, sha512.New, nil
+	}
+	return 0, nil, fmt.Errorf("invalid hashPass func")
+}
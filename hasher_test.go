@@ -0,0 +1,118 @@
+/*  hasher_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 17:30
+ */
+
+package mimir
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testHashers() []PasswordHasher {
+	return []PasswordHasher{
+		&Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1},
+		&BcryptHasher{Cost: bcrypt.MinCost},
+		&ScryptHasher{LogN: 4},
+		&PBKDF2Hasher{Digest: "sha256", Rounds: 1000},
+	}
+}
+
+func TestPasswordHashers(t *testing.T) {
+	hashers := testHashers()
+	for _, hasher := range hashers {
+		hasher := hasher // pin it
+		t.Run(hasher.Identifiers()[0], func(t *testing.T) {
+			registry := NewPasswordHashers(hasher, testHashers()...)
+			hash, err := registry.Hash("sekret")
+			assert.NoError(t, err)
+			assert.Contains(t, hasher.Identifiers(), hashIdentifier(hash))
+
+			other, err := registry.Hash("sekret")
+			assert.NoError(t, err)
+			assert.NotEqual(t, hash, other, "every hash has its own salt")
+
+			ok, err := registry.Verify(hash, "sekret")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			ok, err = registry.Verify(hash, "secret")
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			assert.False(t, registry.NeedsRehash(hash))
+		})
+	}
+}
+
+func TestPasswordHashersAutoDetect(t *testing.T) {
+	argon := &Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}
+	registry := NewPasswordHashers(argon, testHashers()...)
+
+	legacy := HashPassword("sekret", "salted")
+	assert.True(t, strings.HasPrefix(legacy, "$pbkdf2-sha512$25000$"))
+	ok, err := VerifyPassword(legacy, "sekret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	bcryptHash, err := (&BcryptHasher{Cost: bcrypt.MinCost}).Hash("sekret")
+	assert.NoError(t, err)
+	ok, err = registry.Verify(bcryptHash, "sekret")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, registry.NeedsRehash(bcryptHash), "migrate to the preferred hasher")
+
+	_, err = registry.Verify("$md5$salt$hash", "sekret")
+	assert.EqualError(t, err, `unsupported password hash "md5"`)
+	_, err = registry.Verify("plaintext", "sekret")
+	assert.Error(t, err)
+	assert.True(t, registry.NeedsRehash("plaintext"))
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	weak := &Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}
+	hash, err := weak.Hash("sekret")
+	assert.NoError(t, err)
+
+	strong := &Argon2idHasher{Memory: 2048, Iterations: 1, Parallelism: 1}
+	registry := NewPasswordHashers(strong)
+	assert.True(t, registry.NeedsRehash(hash))
+	ok, err := registry.Verify(hash, "sekret")
+	assert.NoError(t, err)
+	assert.True(t, ok, "weaker hashes still verify")
+
+	scryptHash, err := (&ScryptHasher{LogN: 4}).Hash("sekret")
+	assert.NoError(t, err)
+	assert.True(t, (&ScryptHasher{LogN: 5}).NeedsRehash(scryptHash))
+	assert.True(t, (&PBKDF2Hasher{}).NeedsRehash(HashPassword("sekret", "salt")[:10]))
+	assert.False(t, (&PBKDF2Hasher{}).NeedsRehash(HashPassword("sekret", "salt")))
+	assert.True(t, (&BcryptHasher{Cost: bcrypt.MinCost + 1}).NeedsRehash(mustBcrypt(t, bcrypt.MinCost)))
+}
+
+func TestPasswordHashersMalformed(t *testing.T) {
+	for _, hash := range []string{
+		// an empty key verifies any password
+		"$scrypt$ln=4,r=8,p=1$c2FsdHNhbHQ$",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$",
+		// too short salts
+		"$scrypt$ln=4,r=8,p=1$c2FsdA$c2FsdHNhbHRzYWx0c2FsdA",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$c2FsdHNhbHRzYWx0c2FsdA",
+		// the costs are bounded
+		"$argon2id$v=19$m=1073741824,t=1,p=1$c2FsdHNhbHQ$c2FsdHNhbHRzYWx0c2FsdA",
+		"$argon2id$v=19$m=1024,t=100000,p=1$c2FsdHNhbHQ$c2FsdHNhbHRzYWx0c2FsdA",
+		"$argon2id$v=19$m=1024,t=1,p=255$c2FsdHNhbHQ$c2FsdHNhbHRzYWx0c2FsdA",
+	} {
+		ok, err := DefaultPasswordHashers.Verify(hash, "anything")
+		assert.Error(t, err, hash)
+		assert.False(t, ok, hash)
+	}
+}
+
+func mustBcrypt(t *testing.T, cost int) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte("sekret"), cost)
+	assert.NoError(t, err)
+	return string(hash)
+}
@@ -11,6 +11,7 @@ package mimir
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -43,11 +44,17 @@ type (
 		TLS      Https
 		CertFile string
 		KeyFile  string
+		// GracePeriod bounds the drain, the servers run by Application
+		// drain within its own grace period.
+		GracePeriod time.Duration
 	}
 )
 
 func ListenAndServe(opts ServeOpts) (ServerRunFunc, func(context.Context)) {
 	logger := opts.Logger
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = DefaultGracePeriod
+	}
 	httpServer := http.Server{
 		Addr:         fmt.Sprintf(":%d", opts.Port),
 		Handler:      opts.Router,
@@ -68,8 +75,15 @@ func ListenAndServe(opts ServeOpts) (ServerRunFunc, func(context.Context)) {
 	}
 
 	return func(ctx context.Context) error {
-		errChan := make(chan error)
+		errChan := make(chan error, 1)
+		serving := listening(ctx)
 		go func() {
+			n, err := net.Listen("tcp", httpServer.Addr)
+			if err != nil {
+				errChan <- err
+				return
+			}
+			serving()
 			// Description µ micro service
 			fmt.Println(
 				fmt.Sprintf(
@@ -79,9 +93,9 @@ func ListenAndServe(opts ServeOpts) (ServerRunFunc, func(context.Context)) {
 			logger.Info(fmt.Sprintf("Now serving at %s", httpServer.Addr))
 			if opts.TLS {
 				logger.Info("Secure with HTTPS")
-				errChan <- httpServer.ListenAndServeTLS(opts.CertFile, opts.KeyFile)
+				errChan <- httpServer.ServeTLS(n, opts.CertFile, opts.KeyFile)
 			} else {
-				errChan <- httpServer.ListenAndServe()
+				errChan <- httpServer.Serve(n)
 			}
 		}()
 
@@ -89,7 +103,14 @@ func ListenAndServe(opts ServeOpts) (ServerRunFunc, func(context.Context)) {
 		case err := <-errChan:
 			return err
 		case <-ctx.Done():
-			_ = httpServer.Close()
+			// stop accepting new connections and drain the in-flight requests
+			drainCtx, cancel := drainContext(ctx, opts.GracePeriod)
+			defer cancel()
+			logger.Info(fmt.Sprintf("Draining server at %s", httpServer.Addr))
+			if err := httpServer.Shutdown(drainCtx); err != nil {
+				logger.Warnf("Drain is over due to %v, closing server", err)
+				_ = httpServer.Close()
+			}
 			return fmt.Errorf("server interrupted through context")
 		}
 	}, cleanup
@@ -116,14 +137,18 @@ const (
 	StatusUnauthorized          = http.StatusUnauthorized
 	StatusPaymentRequired       = http.StatusPaymentRequired
 	StatusForbidden             = http.StatusForbidden
+	StatusNotFound              = http.StatusNotFound
 	StatusMethodNotAllowed      = http.StatusMethodNotAllowed
 	StatusNotAcceptable         = http.StatusNotAcceptable
 	StatusInvalidAuthentication = http.StatusProxyAuthRequired
 	StatusRequestTimeout        = http.StatusRequestTimeout
 	StatusUnsupportedMediaType  = http.StatusUnsupportedMediaType
+	StatusConflict              = http.StatusConflict
 	StatusUnProcess             = http.StatusUnprocessableEntity
+	StatusTooManyRequests       = http.StatusTooManyRequests
 	//5xx
 	StatusInternalError           = http.StatusInternalServerError
+	StatusNotImplementedError     = http.StatusNotImplemented
 	StatusBadGatewayError         = http.StatusBadGateway
 	StatusServiceUnavailableError = http.StatusServiceUnavailable
 	StatusGatewayTimeoutError     = http.StatusGatewayTimeout
@@ -140,14 +165,18 @@ var statusMap = map[int][]string{
 	StatusUnauthorized:          {"STATUS_UNAUTHORIZED", "Not authorized to access the service"},
 	StatusPaymentRequired:       {"STATUS_PAYMENT_REQUIRED", "Payment need to be done"},
 	StatusForbidden:             {"STATUS_FORBIDDEN", "Forbidden access the resource "},
+	StatusNotFound:              {"STATUS_NOT_FOUND", "Resource not found"},
 	StatusMethodNotAllowed:      {"STATUS_METHOD_NOT_ALLOWED", "The method specified is not allowed"},
 	StatusNotAcceptable:         {"STATUS_NOT_ACCEPTABLE", "Request cannot accepted"},
 	StatusInvalidAuthentication: {"STATUS_INVALID_AUTHENTICATION", "The resource owner or authorization server denied the request"},
 	StatusRequestTimeout:        {"STATUS_REQUEST_TIMEOUT", "Request Timeout"},
 	StatusUnsupportedMediaType:  {"STATUS_UNSUPPORTED_MEDIA_TYPE", "Cannot understand request content"},
+	StatusConflict:              {"STATUS_CONFLICT", "Resource conflicts with its current state"},
 	StatusUnProcess:             {"STATUS_UNPROCESSABLE_ENTITY", "Unable to process the contained instructions"},
+	StatusTooManyRequests:       {"STATUS_TOO_MANY_REQUESTS", "Too many requests"},
 
 	StatusInternalError:           {"INTERNAL_SERVER_ERROR", "Oops something went wrong"},
+	StatusNotImplementedError:     {"STATUS_NOT_IMPLEMENTED_ERROR", "Not implemented"},
 	StatusBadGatewayError:         {"STATUS_BAD_GATEWAY_ERROR", "Oops something went wrong"},
 	StatusServiceUnavailableError: {"STATUS_SERVICE_UNAVAILABLE_ERROR", "Service Unavailable"},
 	StatusGatewayTimeoutError:     {"STATUS_GATEWAY_TIMEOUT_ERROR", "Gateway Timeout"},
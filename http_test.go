@@ -51,6 +51,17 @@ func findOpenPort() (int, error) {
 	return 0, fmt.Errorf("could not find port to use for testing (%d attempts)", attempts)
 }
 
+func waitForPort(port int) error {
+	for i := 0; i < 100; i++ {
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		if err == nil {
+			return conn.Close()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fmt.Errorf("port %d is not listening", port)
+}
+
 func TestHttpListenAndServe(t *testing.T) {
 	logger := With(
 		Field("logger suki", "TestHttpListenAndServe"),
@@ -76,6 +87,8 @@ func TestHttpListenAndServe(t *testing.T) {
 	go func() {
 		serverErrChan <- runServer(ctx)
 	}()
+	// the server listens in the background
+	assert.NoError(t, waitForPort(port))
 
 	resp, queryErr := http.Get(fmt.Sprintf("http://localhost:%d", port))
 
@@ -98,3 +111,56 @@ func TestHttpListenAndServe(t *testing.T) {
 		assert.Fail(t, "server never quit")
 	}
 }
+
+func TestHttpListenAndServeDrain(t *testing.T) {
+	port, err := findOpenPort()
+	if err != nil {
+		assert.Fail(t, "could not find a testing port")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inFlight := make(chan struct{})
+	router := ruuto.NewChiRouter()
+	router.GET("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(inFlight)
+		time.Sleep(100 * time.Millisecond)
+		Response(r).APIStatusSuccess(w, r).WriteJSON()
+	})
+
+	runServer, cleanup := ListenAndServe(ServeOpts{
+		Logger:      With(Field("testing", "TestHttpListenAndServeDrain")),
+		Port:        WebPort(port),
+		Router:      router,
+		TimeOut:     WebTimeOut(10),
+		GracePeriod: time.Second,
+	})
+	serverErrChan := make(chan error)
+	go func() {
+		serverErrChan <- runServer(ctx)
+	}()
+	assert.NoError(t, waitForPort(port))
+
+	respChan := make(chan int)
+	go func() {
+		resp, err := http.Get(fmt.Sprintf("http://localhost:%d/slow", port))
+		if err != nil {
+			respChan <- 0
+			return
+		}
+		_ = resp.Body.Close()
+		respChan <- resp.StatusCode
+	}()
+
+	<-inFlight
+	cancel()
+
+	assert.Equal(t, http.StatusOK, <-respChan)
+	select {
+	case serverErr := <-serverErrChan:
+		assert.EqualError(t, serverErr, "server interrupted through context")
+		cleanup(context.Background())
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "server never quit")
+	}
+}
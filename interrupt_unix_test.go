@@ -0,0 +1,99 @@
+//go:build !windows
+// +build !windows
+
+/*  interrupt_unix_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 11:20
+ */
+
+package mimir
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationSignals(t *testing.T) {
+	log, ts := newZap(t)
+	interrupt := make(chan os.Signal, 4)
+
+	reloaded := make(chan struct{}, 1)
+	cleanup, err := ApplicationWithOpts(ApplicationOpts{
+		Interrupt: interrupt,
+		Logger:    log,
+		Reload: func() error {
+			reloaded <- struct{}{}
+			return nil
+		},
+	}, func(c context.Context) (AppRunner, func(), error) {
+		return func(ctx context.Context) error {
+			interrupt <- syscall.SIGHUP
+			<-reloaded
+			interrupt <- syscall.SIGUSR1
+			interrupt <- syscall.SIGTERM
+			<-ctx.Done()
+			return nil
+		}, func() {}, nil
+	})
+
+	var interrupted ErrInterrupted
+	assert.True(t, errors.As(err, &interrupted))
+	assert.Equal(t, syscall.SIGTERM, interrupted.Signal)
+	assert.NotNil(t, cleanup)
+
+	var dumped bool
+	for _, msg := range ts.Messages {
+		if strings.Contains(msg, "Goroutine dump") && strings.Contains(msg, "goroutine ") {
+			dumped = true
+		}
+	}
+	assert.True(t, dumped, "goroutine stacks should be logged on SIGUSR1")
+}
+
+func TestApplicationForceExit(t *testing.T) {
+	interrupt := make(chan os.Signal, 4)
+	interrupt <- syscall.SIGTERM
+	interrupt <- syscall.SIGINT
+
+	var forced bool
+	_, err := ApplicationWithOpts(ApplicationOpts{
+		Interrupt:   interrupt,
+		GracePeriod: time.Second,
+		ForceExit: func() {
+			forced = true
+		},
+	}, func(c context.Context) (AppRunner, func(), error) {
+		return func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(time.Second) // stuck while draining
+			return nil
+		}, func() {}, nil
+	})
+
+	assert.True(t, forced)
+	var interrupted ErrInterrupted
+	assert.True(t, errors.As(err, &interrupted))
+	assert.Equal(t, syscall.SIGINT, interrupted.Signal)
+}
+
+func TestSignalChannelFunc(t *testing.T) {
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		assert.Fail(t, "failed to find my process: %v", err)
+	}
+
+	interrupt := SignalChannelFunc()
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		assert.Fail(t, "failed to send hangup signal: %v", err)
+	}
+
+	assert.Equal(t, syscall.SIGHUP, <-interrupt)
+}
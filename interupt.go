@@ -11,6 +11,7 @@ package mimir
 import (
 	"os"
 	"os/signal"
+	"runtime"
 	"syscall"
 )
 
@@ -21,3 +22,25 @@ func InterruptChannelFunc() InterruptChannel {
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
 	return interrupt
 }
+
+// SignalChannelFunc subscribes to the shutdown signals along with the reload
+// (SIGHUP) and goroutine dump (SIGUSR1) signals handled by Application.
+func SignalChannelFunc() InterruptChannel {
+	interrupt := make(chan os.Signal, 4)
+	signal.Notify(interrupt, handledSignals...)
+	return interrupt
+}
+
+// dumpGoroutines writes the stacks of every goroutine through the logger.
+func dumpGoroutines(logger Logging) {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	logger.Info("Goroutine dump", logger.Field("goroutines", runtime.NumGoroutine()), logger.Field("stacks", string(buf)))
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+/*  interupt_unix.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 11:02
+ */
+
+package mimir
+
+import (
+	"os"
+	"syscall"
+)
+
+var handledSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1}
+
+func isReloadSignal(sig os.Signal) bool {
+	return sig == syscall.SIGHUP
+}
+
+func isDumpSignal(sig os.Signal) bool {
+	return sig == syscall.SIGUSR1
+}
@@ -0,0 +1,25 @@
+//go:build windows
+// +build windows
+
+/*  interupt_windows.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 11:02
+ */
+
+package mimir
+
+import (
+	"os"
+	"syscall"
+)
+
+var handledSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+func isReloadSignal(sig os.Signal) bool {
+	return false
+}
+
+func isDumpSignal(sig os.Signal) bool {
+	return false
+}
@@ -0,0 +1,421 @@
+/*  jwt.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 18:10
+ */
+
+package mimir
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+var (
+	ErrTokenMalformed   = errors.New("token is malformed")
+	ErrTokenSignature   = errors.New("token signature is invalid")
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+	ErrTokenIssuer      = errors.New("token issuer is invalid")
+	ErrTokenAudience    = errors.New("token audience is invalid")
+	ErrTokenUnknownKey  = errors.New("token key is unknown")
+	ErrTokenUnsupported = errors.New("token algorithm is unsupported")
+)
+
+var registeredClaimNames = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+type (
+	// TokenIssuer signs or encrypts claims into a token.
+	TokenIssuer interface {
+		Issue(claims Claims) (string, error)
+	}
+
+	// TokenVerifier checks a token and validates its claims.
+	TokenVerifier interface {
+		Verify(token string) (*Claims, error)
+	}
+
+	// Claims are the registered claims of a token, the other claims are in Extra.
+	Claims struct {
+		Issuer    string
+		Subject   string
+		Audience  []string
+		ExpiresAt time.Time
+		NotBefore time.Time
+		IssuedAt  time.Time
+		ID        string
+		Extra     map[string]interface{}
+	}
+
+	// ClaimsOpts validates the claims of the verified tokens,
+	// the issuer and the audience are checked when they are set.
+	ClaimsOpts struct {
+		Issuer   string
+		Audience string
+		Leeway   time.Duration
+		Clock    Clock
+	}
+
+	// JWTKey is a signing or verification key, Key is a []byte for HS256,
+	// an *rsa.PrivateKey or *rsa.PublicKey for RS256 and an
+	// ed25519.PrivateKey or ed25519.PublicKey for EdDSA.
+	JWTKey struct {
+		ID        string
+		Algorithm string
+		Key       interface{}
+	}
+
+	JWTOpts struct {
+		SigningKey *JWTKey
+		VerifyKeys []JWTKey
+		Claims     ClaimsOpts
+	}
+)
+
+// Validate checks the time, issuer and audience claims.
+func (c *Claims) Validate(opts ClaimsOpts) error {
+	clock := opts.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	now := clock.Now()
+	if !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt.Add(opts.Leeway)) {
+		return ErrTokenExpired
+	}
+	if !c.NotBefore.IsZero() && now.Add(opts.Leeway).Before(c.NotBefore) {
+		return ErrTokenNotYetValid
+	}
+	if opts.Issuer != "" && c.Issuer != opts.Issuer {
+		return ErrTokenIssuer
+	}
+	if opts.Audience != "" {
+		for _, aud := range c.Audience {
+			if aud == opts.Audience {
+				return nil
+			}
+		}
+		return ErrTokenAudience
+	}
+	return nil
+}
+
+// toMap flattens the claims, the times are NumericDate for JWT
+// and RFC 3339 for PASETO.
+func (c *Claims) toMap(numeric bool) map[string]interface{} {
+	m := make(map[string]interface{}, len(c.Extra)+7)
+	for k, v := range c.Extra {
+		m[k] = v
+	}
+	set := func(key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	setTime := func(key string, t time.Time) {
+		if t.IsZero() {
+			return
+		}
+		if numeric {
+			m[key] = t.Unix()
+			return
+		}
+		m[key] = t.UTC().Format(time.RFC3339)
+	}
+	set("iss", c.Issuer)
+	set("sub", c.Subject)
+	set("jti", c.ID)
+	switch len(c.Audience) {
+	case 0:
+	case 1:
+		m["aud"] = c.Audience[0]
+	default:
+		m["aud"] = c.Audience
+	}
+	setTime("exp", c.ExpiresAt)
+	setTime("nbf", c.NotBefore)
+	setTime("iat", c.IssuedAt)
+	return m
+}
+
+func claimsFromJSON(payload []byte) (*Claims, error) {
+	var m map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(string(payload)))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	c := &Claims{Extra: make(map[string]interface{})}
+	str := func(key string) (string, error) {
+		v, ok := m[key]
+		if !ok {
+			return "", nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: claim %s is not a string", ErrTokenMalformed, key)
+		}
+		return s, nil
+	}
+	tm := func(key string) (time.Time, error) {
+		switch v := m[key].(type) {
+		case nil:
+			return time.Time{}, nil
+		case json.Number:
+			f, err := v.Float64()
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w: claim %s is not a date", ErrTokenMalformed, key)
+			}
+			return time.Unix(0, int64(f*float64(time.Second))), nil
+		case string:
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w: claim %s is not a date", ErrTokenMalformed, key)
+			}
+			return t, nil
+		}
+		return time.Time{}, fmt.Errorf("%w: claim %s is not a date", ErrTokenMalformed, key)
+	}
+
+	var err error
+	if c.Issuer, err = str("iss"); err != nil {
+		return nil, err
+	}
+	if c.Subject, err = str("sub"); err != nil {
+		return nil, err
+	}
+	if c.ID, err = str("jti"); err != nil {
+		return nil, err
+	}
+	switch aud := m["aud"].(type) {
+	case nil:
+	case string:
+		c.Audience = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			s, ok := a.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: claim aud is not a string", ErrTokenMalformed)
+			}
+			c.Audience = append(c.Audience, s)
+		}
+	default:
+		return nil, fmt.Errorf("%w: claim aud is not a string", ErrTokenMalformed)
+	}
+	if c.ExpiresAt, err = tm("exp"); err != nil {
+		return nil, err
+	}
+	if c.NotBefore, err = tm("nbf"); err != nil {
+		return nil, err
+	}
+	if c.IssuedAt, err = tm("iat"); err != nil {
+		return nil, err
+	}
+
+	registered := make(map[string]bool, len(registeredClaimNames))
+	for _, name := range registeredClaimNames {
+		registered[name] = true
+	}
+	for k, v := range m {
+		if !registered[k] {
+			c.Extra[k] = v
+		}
+	}
+	return c, nil
+}
+
+// JWT issues and verifies JSON Web Tokens in compact serialization.
+type JWT struct {
+	opts JWTOpts
+	mu   sync.RWMutex
+	keys []JWTKey
+}
+
+func NewJWT(opts JWTOpts) *JWT {
+	j := &JWT{opts: opts}
+	j.SetVerifyKeys(opts.VerifyKeys...)
+	return j
+}
+
+// SetVerifyKeys replaces the verification keys, e.g. after a JWKS refresh.
+// The public part of the signing key always verifies.
+func (j *JWT) SetVerifyKeys(keys ...JWTKey) {
+	all := append([]JWTKey(nil), keys...)
+	if sk := j.opts.SigningKey; sk != nil {
+		all = append(all, JWTKey{ID: sk.ID, Algorithm: sk.Algorithm, Key: publicKey(sk.Key)})
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = all
+}
+
+func (j *JWT) Issue(claims Claims) (string, error) {
+	key := j.opts.SigningKey
+	if key == nil {
+		return "", fmt.Errorf("jwt: no signing key")
+	}
+	header := map[string]string{"alg": key.Algorithm, "typ": "JWT"}
+	if key.ID != "" {
+		header["kid"] = key.ID
+	}
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	p, err := json.Marshal(claims.toMap(true))
+	if err != nil {
+		return "", err
+	}
+	signing := b64url.EncodeToString(h) + "." + b64url.EncodeToString(p)
+	sig, err := jwtSign(key, []byte(signing))
+	if err != nil {
+		return "", err
+	}
+	return signing + "." + b64url.EncodeToString(sig), nil
+}
+
+// Verify checks the signature with the key of the token kid, or with every
+// key of the token algorithm when it has none, then validates the claims.
+func (j *JWT) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+	h, err := b64url.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(h, &header); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	sig, err := b64url.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	j.mu.RLock()
+	keys := j.keys
+	j.mu.RUnlock()
+
+	signing := []byte(parts[0] + "." + parts[1])
+	found, verified := false, false
+	for i := range keys {
+		key := &keys[i]
+		// the algorithm of the key wins over the one of the token
+		if key.Algorithm != header.Alg || (header.Kid != "" && key.ID != header.Kid) {
+			continue
+		}
+		found = true
+		if jwtVerify(key, signing, sig) {
+			verified = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrTokenUnknownKey
+	}
+	if !verified {
+		return nil, ErrTokenSignature
+	}
+
+	payload, err := b64url.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	claims, err := claimsFromJSON(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := claims.Validate(j.opts.Claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+var b64url = base64.RawURLEncoding
+
+func jwtSign(key *JWTKey, signing []byte) ([]byte, error) {
+	switch key.Algorithm {
+	case HS256:
+		secret, ok := key.Key.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("jwt: HS256 needs a []byte key")
+		}
+		mac := hmac.New(sha256.New, secret)
+		_, _ = mac.Write(signing)
+		return mac.Sum(nil), nil
+	case RS256:
+		private, ok := key.Key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt: RS256 needs an *rsa.PrivateKey")
+		}
+		digest := sha256.Sum256(signing)
+		return rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, digest[:])
+	case EdDSA:
+		private, ok := key.Key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt: EdDSA needs an ed25519.PrivateKey")
+		}
+		return ed25519.Sign(private, signing), nil
+	}
+	return nil, ErrTokenUnsupported
+}
+
+func jwtVerify(key *JWTKey, signing, sig []byte) bool {
+	switch key.Algorithm {
+	case HS256:
+		secret, ok := key.Key.([]byte)
+		if !ok {
+			return false
+		}
+		mac := hmac.New(sha256.New, secret)
+		_, _ = mac.Write(signing)
+		return hmac.Equal(sig, mac.Sum(nil))
+	case RS256:
+		public, ok := key.Key.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		digest := sha256.Sum256(signing)
+		return rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], sig) == nil
+	case EdDSA:
+		public, ok := key.Key.(ed25519.PublicKey)
+		if !ok || len(public) != ed25519.PublicKeySize {
+			return false
+		}
+		return ed25519.Verify(public, signing, sig)
+	}
+	return false
+}
+
+// publicKey returns the verification key of a signing key.
+func publicKey(key interface{}) interface{} {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public()
+	}
+	return key
+}
@@ -0,0 +1,137 @@
+/*  jwt_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 19:30
+ */
+
+package mimir
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTRoundTrip(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	clock := newFakeClock()
+	claims := Claims{
+		Issuer:    "mimir",
+		Subject:   "42",
+		Audience:  []string{"booking"},
+		IssuedAt:  clock.Now(),
+		ExpiresAt: clock.Now().Add(time.Hour),
+		Extra:     map[string]interface{}{"role": "admin"},
+	}
+	for _, key := range []JWTKey{
+		{ID: "hs", Algorithm: HS256, Key: []byte("secret")},
+		{ID: "rs", Algorithm: RS256, Key: rsaKey},
+		{ID: "ed", Algorithm: EdDSA, Key: edKey},
+	} {
+		key := key
+		t.Run(key.Algorithm, func(t *testing.T) {
+			j := NewJWT(JWTOpts{
+				SigningKey: &key,
+				Claims:     ClaimsOpts{Issuer: "mimir", Audience: "booking", Clock: clock},
+			})
+			token, err := j.Issue(claims)
+			require.NoError(t, err)
+			assert.Len(t, strings.Split(token, "."), 3)
+
+			got, err := j.Verify(token)
+			require.NoError(t, err)
+			assert.Equal(t, "42", got.Subject)
+			assert.Equal(t, []string{"booking"}, got.Audience)
+			assert.True(t, claims.ExpiresAt.Equal(got.ExpiresAt))
+			assert.Equal(t, "admin", got.Extra["role"])
+
+			// a flipped signature byte
+			tampered := []byte(token)
+			tampered[len(tampered)-2] ^= 'A' ^ 'B'
+			_, err = j.Verify(string(tampered))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestJWTVerifyVector(t *testing.T) {
+	// HS256 example of jwt.io
+	token := "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+		"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ." +
+		"SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+	j := NewJWT(JWTOpts{VerifyKeys: []JWTKey{{Algorithm: HS256, Key: []byte("your-256-bit-secret")}}})
+
+	claims, err := j.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890", claims.Subject)
+	assert.Equal(t, "John Doe", claims.Extra["name"])
+	assert.Equal(t, int64(1516239022), claims.IssuedAt.Unix())
+
+	j = NewJWT(JWTOpts{VerifyKeys: []JWTKey{{Algorithm: HS256, Key: []byte("another-secret")}}})
+	_, err = j.Verify(token)
+	assert.Equal(t, ErrTokenSignature, err)
+}
+
+func TestJWTAlgorithmConfusion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	j := NewJWT(JWTOpts{VerifyKeys: []JWTKey{{ID: "rs", Algorithm: RS256, Key: &rsaKey.PublicKey}}})
+
+	// none is never accepted
+	none := b64url.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		b64url.EncodeToString([]byte(`{"sub":"42"}`)) + "."
+	_, err = j.Verify(none)
+	assert.Equal(t, ErrTokenUnknownKey, err)
+
+	// an HS256 token keyed with the public key bytes of the RS256 key
+	forger := NewJWT(JWTOpts{SigningKey: &JWTKey{ID: "rs", Algorithm: HS256, Key: rsaKey.PublicKey.N.Bytes()}})
+	forged, err := forger.Issue(Claims{Subject: "42"})
+	require.NoError(t, err)
+	_, err = j.Verify(forged)
+	assert.Equal(t, ErrTokenUnknownKey, err)
+
+	// kid of another key
+	signer := NewJWT(JWTOpts{SigningKey: &JWTKey{ID: "other", Algorithm: RS256, Key: rsaKey}})
+	token, err := signer.Issue(Claims{Subject: "42"})
+	require.NoError(t, err)
+	_, err = j.Verify(token)
+	assert.Equal(t, ErrTokenUnknownKey, err)
+
+	_, err = j.Verify("not.a-token")
+	assert.Equal(t, ErrTokenMalformed, err)
+}
+
+func TestClaimsValidate(t *testing.T) {
+	clock := newFakeClock()
+	now := clock.Now()
+	opts := ClaimsOpts{Issuer: "mimir", Audience: "booking", Leeway: time.Minute, Clock: clock}
+
+	cases := []struct {
+		name   string
+		claims Claims
+		err    error
+	}{
+		{"valid", Claims{Issuer: "mimir", Audience: []string{"payment", "booking"}, ExpiresAt: now.Add(time.Second)}, nil},
+		{"expired", Claims{Issuer: "mimir", Audience: []string{"booking"}, ExpiresAt: now.Add(-2 * time.Minute)}, ErrTokenExpired},
+		{"expired within leeway", Claims{Issuer: "mimir", Audience: []string{"booking"}, ExpiresAt: now.Add(-time.Second)}, nil},
+		{"not yet valid", Claims{Issuer: "mimir", Audience: []string{"booking"}, NotBefore: now.Add(2 * time.Minute)}, ErrTokenNotYetValid},
+		{"issuer", Claims{Issuer: "other", Audience: []string{"booking"}}, ErrTokenIssuer},
+		{"audience", Claims{Issuer: "mimir", Audience: []string{"payment"}}, ErrTokenAudience},
+		{"no audience", Claims{Issuer: "mimir"}, ErrTokenAudience},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.err, c.claims.Validate(opts))
+		})
+	}
+}
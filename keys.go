@@ -0,0 +1,175 @@
+/*  keys.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 19:05
+ */
+
+package mimir
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+)
+
+// ParsePrivateKeyPEM parses an RSA key in PKCS #1 or PKCS #8
+// and an Ed25519 key in PKCS #8.
+func ParsePrivateKeyPEM(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey, ed25519.PrivateKey:
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported private key %T", key)
+}
+
+// ParsePublicKeyPEM parses an RSA key in PKCS #1 or PKIX
+// and an Ed25519 key in PKIX.
+func ParsePublicKeyPEM(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	switch k := key.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported public key %T", key)
+}
+
+func LoadPrivateKey(path string) (interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKeyPEM(data)
+}
+
+func LoadPublicKey(path string) (interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKeyPEM(data)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS reads the verification keys of a JSON Web Key Set, RSA, Ed25519
+// and symmetric keys are supported, the others and the encryption keys are
+// skipped.
+func ParseJWKS(r io.Reader) ([]JWTKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(r).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make([]JWTKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key := JWTKey{ID: k.Kid, Algorithm: k.Alg}
+		switch k.Kty {
+		case "RSA":
+			n, err := b64url.DecodeString(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("jwk %s: invalid modulus", k.Kid)
+			}
+			e, err := b64url.DecodeString(k.E)
+			if err != nil || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("jwk %s: invalid exponent", k.Kid)
+			}
+			key.Key = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+			if key.Algorithm == "" {
+				key.Algorithm = RS256
+			}
+		case "OKP":
+			if k.Crv != "Ed25519" {
+				continue
+			}
+			x, err := b64url.DecodeString(k.X)
+			if err != nil || len(x) != ed25519.PublicKeySize {
+				return nil, fmt.Errorf("jwk %s: invalid public key", k.Kid)
+			}
+			key.Key = ed25519.PublicKey(x)
+			key.Algorithm = EdDSA
+		case "oct":
+			secret, err := b64url.DecodeString(k.K)
+			if err != nil {
+				return nil, fmt.Errorf("jwk %s: invalid secret", k.Kid)
+			}
+			key.Key = secret
+			if key.Algorithm == "" {
+				key.Algorithm = HS256
+			}
+		default:
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// FetchJWKS downloads and parses a JSON Web Key Set, client defaults to
+// http.DefaultClient.
+func FetchJWKS(ctx context.Context, client *http.Client, url string) ([]JWTKey, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(ApplicationJSON))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		drainBody(resp.Body)
+		return nil, fmt.Errorf("fetch jwks: %w", &statusError{code: resp.StatusCode})
+	}
+	return ParseJWKS(resp.Body)
+}
@@ -0,0 +1,118 @@
+/*  keys_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 19:50
+ */
+
+package mimir
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "mimir-keys")
+	require.NoError(t, err)
+	write := func(name, typ string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600))
+		return path
+	}
+	pkcs8 := func(key interface{}) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return der
+	}
+	pkix := func(key interface{}) []byte {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		require.NoError(t, err)
+		return der
+	}
+
+	private, err := LoadPrivateKey(write("rsa1.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)))
+	require.NoError(t, err)
+	assert.Equal(t, rsaKey.N, private.(*rsa.PrivateKey).N)
+	private, err = LoadPrivateKey(write("rsa8.pem", "PRIVATE KEY", pkcs8(rsaKey)))
+	require.NoError(t, err)
+	assert.Equal(t, rsaKey.N, private.(*rsa.PrivateKey).N)
+	private, err = LoadPrivateKey(write("ed.pem", "PRIVATE KEY", pkcs8(edPrivate)))
+	require.NoError(t, err)
+	assert.Equal(t, edPrivate, private)
+
+	public, err := LoadPublicKey(write("rsa1.pub", "RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)))
+	require.NoError(t, err)
+	assert.Equal(t, &rsaKey.PublicKey, public)
+	public, err = LoadPublicKey(write("rsa.pub", "PUBLIC KEY", pkix(&rsaKey.PublicKey)))
+	require.NoError(t, err)
+	assert.Equal(t, &rsaKey.PublicKey, public)
+	public, err = LoadPublicKey(write("ed.pub", "PUBLIC KEY", pkix(edPublic)))
+	require.NoError(t, err)
+	assert.Equal(t, edPublic, public)
+
+	_, err = ParsePrivateKeyPEM([]byte("not a key"))
+	assert.Error(t, err)
+	_, err = LoadPublicKey(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	set := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rs", "use": "sig",
+			"n": b64url.EncodeToString(rsaKey.N.Bytes()),
+			"e": b64url.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64url.EncodeToString(edPublic)},
+		{"kty": "oct", "kid": "hs", "k": b64url.EncodeToString([]byte("secret"))},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		{"kty": "EC", "kid": "ec", "crv": "P-256"},
+	}}
+	buf, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	keys, err := ParseJWKS(strings.NewReader(string(buf)))
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+	assert.Equal(t, JWTKey{ID: "rs", Algorithm: RS256, Key: &rsaKey.PublicKey}, keys[0])
+	assert.Equal(t, JWTKey{ID: "ed", Algorithm: EdDSA, Key: edPublic}, keys[1])
+	assert.Equal(t, JWTKey{ID: "hs", Algorithm: HS256, Key: []byte("secret")}, keys[2])
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(buf)
+	}))
+	defer srv.Close()
+	keys, err = FetchJWKS(context.Background(), nil, srv.URL)
+	require.NoError(t, err)
+
+	// tokens of the set keys verify
+	signer := NewJWT(JWTOpts{SigningKey: &JWTKey{ID: "ed", Algorithm: EdDSA, Key: edPrivate}})
+	token, err := signer.Issue(Claims{Subject: "42"})
+	require.NoError(t, err)
+	claims, err := NewJWT(JWTOpts{VerifyKeys: keys}).Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "42", claims.Subject)
+}
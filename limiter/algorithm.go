@@ -0,0 +1,161 @@
+/*  algorithm.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 14:10
+ */
+
+package limiter
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	DefaultLimit        = 20
+	DefaultMinLimit     = 1
+	DefaultMaxLimit     = 1000
+	DefaultBackoffRatio = 0.9
+	DefaultSmoothing    = 0.2
+	DefaultProbeSamples = 1000
+)
+
+// Fixed is a bulkhead of n concurrent requests.
+type Fixed int
+
+func NewFixed(n int) Fixed {
+	if n <= 0 {
+		n = DefaultLimit
+	}
+	return Fixed(n)
+}
+
+func (f Fixed) Limit() int { return int(f) }
+
+func (f Fixed) Update(Sample) {}
+
+// AIMDOpts configures the additive increase, multiplicative decrease limit.
+type AIMDOpts struct {
+	Initial      int
+	Min          int
+	Max          int
+	BackoffRatio float64
+	// Timeout marks the slower requests as dropped, zero disables it.
+	Timeout time.Duration
+}
+
+// AIMD grows the limit by one while requests succeed with the limit in
+// use and multiplies it by BackoffRatio on every drop.
+type AIMD struct {
+	opts  AIMDOpts
+	limit int
+}
+
+func NewAIMD(opts AIMDOpts) *AIMD {
+	if opts.Min <= 0 {
+		opts.Min = DefaultMinLimit
+	}
+	if opts.Max <= 0 {
+		opts.Max = DefaultMaxLimit
+	}
+	if opts.Initial <= 0 {
+		opts.Initial = DefaultLimit
+	}
+	if opts.BackoffRatio <= 0 || opts.BackoffRatio >= 1 {
+		opts.BackoffRatio = DefaultBackoffRatio
+	}
+	return &AIMD{opts: opts, limit: clamp(opts.Initial, opts.Min, opts.Max)}
+}
+
+func (a *AIMD) Limit() int { return a.limit }
+
+func (a *AIMD) Update(s Sample) {
+	if s.Dropped || (a.opts.Timeout > 0 && s.RTT > a.opts.Timeout) {
+		a.limit = clamp(int(float64(a.limit)*a.opts.BackoffRatio), a.opts.Min, a.opts.Max)
+		return
+	}
+	// only grow when the limit is actually in use
+	if s.InFlight*2 >= a.limit {
+		a.limit = clamp(a.limit+1, a.opts.Min, a.opts.Max)
+	}
+}
+
+// GradientOpts configures the latency gradient limit.
+type GradientOpts struct {
+	Initial int
+	Min     int
+	Max     int
+	// Smoothing weights the new limit against the previous one.
+	Smoothing float64
+	// ProbeSamples is the number of samples after which the no-load
+	// latency is measured again.
+	ProbeSamples int
+}
+
+// Gradient adapts the limit Vegas-style to the ratio between the no-load
+// latency and the sampled latency: the limit shrinks as queueing grows
+// the latency and grows by a queue allowance of sqrt(limit) otherwise.
+type Gradient struct {
+	opts    GradientOpts
+	limit   float64
+	minRTT  time.Duration
+	samples int
+}
+
+func NewGradient(opts GradientOpts) *Gradient {
+	if opts.Min <= 0 {
+		opts.Min = DefaultMinLimit
+	}
+	if opts.Max <= 0 {
+		opts.Max = DefaultMaxLimit
+	}
+	if opts.Initial <= 0 {
+		opts.Initial = DefaultLimit
+	}
+	if opts.Smoothing <= 0 || opts.Smoothing > 1 {
+		opts.Smoothing = DefaultSmoothing
+	}
+	if opts.ProbeSamples <= 0 {
+		opts.ProbeSamples = DefaultProbeSamples
+	}
+	return &Gradient{opts: opts, limit: float64(clamp(opts.Initial, opts.Min, opts.Max))}
+}
+
+func (g *Gradient) Limit() int { return int(g.limit) }
+
+func (g *Gradient) Update(s Sample) {
+	g.samples++
+	if g.samples >= g.opts.ProbeSamples {
+		g.samples, g.minRTT = 0, 0
+	}
+	if s.RTT <= 0 {
+		return
+	}
+	if g.minRTT == 0 || s.RTT < g.minRTT {
+		g.minRTT = s.RTT
+	}
+
+	limit := g.limit
+	if s.Dropped {
+		limit = limit / 2
+	} else {
+		// an idle limiter tells nothing about the latency under load
+		if float64(s.InFlight) < limit/2 {
+			return
+		}
+		gradient := math.Max(0.5, math.Min(1, float64(g.minRTT)/float64(s.RTT)))
+		limit = limit*gradient + math.Sqrt(limit)
+	}
+	limit = g.limit*(1-g.opts.Smoothing) + limit*g.opts.Smoothing
+	g.limit = math.Max(float64(g.opts.Min), math.Min(float64(g.opts.Max), limit))
+}
+
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
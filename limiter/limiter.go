@@ -0,0 +1,172 @@
+/*  limiter.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 13:50
+ */
+
+// Package limiter sheds load by bounding the requests in flight, the bound
+// is either fixed or adapted to the observed latency.
+package limiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/suryakencana007/mimir"
+)
+
+var ErrLimitExceeded = errors.New("concurrency limit exceeded")
+
+type (
+	// Sample is the outcome of a request, Dropped marks a request that
+	// timed out or was shed downstream, signalling an overload.
+	Sample struct {
+		RTT      time.Duration
+		InFlight int
+		Dropped  bool
+	}
+
+	// Algorithm computes the concurrency limit. Update is called with the
+	// limiter lock held, an Algorithm needs no locking of its own.
+	Algorithm interface {
+		Limit() int
+		Update(Sample)
+	}
+
+	Opts struct {
+		Name      string
+		Algorithm Algorithm
+		// MaxWait is how long Acquire waits for a slot,
+		// zero rejects at once when the limit is reached.
+		MaxWait time.Duration
+		Clock   mimir.Clock
+		Logger  mimir.Logging
+	}
+)
+
+// Limiter bounds the requests in flight to the limit of its algorithm.
+type Limiter struct {
+	opts     Opts
+	mu       sync.Mutex
+	inflight int
+	limit    int
+	released chan struct{}
+}
+
+func New(opts Opts) *Limiter {
+	if opts.Algorithm == nil {
+		opts.Algorithm = NewFixed(DefaultLimit)
+	}
+	if opts.Clock == nil {
+		opts.Clock = mimir.SystemClock
+	}
+	if opts.Logger == nil {
+		opts.Logger = mimir.With(mimir.Field("limiter", opts.Name))
+	}
+	return &Limiter{
+		opts:     opts,
+		limit:    opts.Algorithm.Limit(),
+		released: make(chan struct{}),
+	}
+}
+
+// Acquire takes a slot, waiting at most MaxWait for one. The token must be
+// released once the request completed.
+func (l *Limiter) Acquire(ctx context.Context) (*Token, error) {
+	var wait <-chan time.Time
+	for {
+		l.mu.Lock()
+		if l.inflight < l.limit {
+			l.inflight++
+			token := &Token{limiter: l, start: l.opts.Clock.Now(), inflight: l.inflight}
+			l.mu.Unlock()
+			return token, nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if l.opts.MaxWait <= 0 {
+			return nil, ErrLimitExceeded
+		}
+		if wait == nil {
+			timer := time.NewTimer(l.opts.MaxWait)
+			defer timer.Stop()
+			wait = timer.C
+		}
+		select {
+		case <-released:
+		case <-wait:
+			return nil, ErrLimitExceeded
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// InFlight returns the requests holding a slot.
+func (l *Limiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inflight
+}
+
+func (l *Limiter) release(sample Sample, update bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	if update {
+		l.opts.Algorithm.Update(sample)
+		if limit := l.opts.Algorithm.Limit(); limit != l.limit {
+			l.opts.Logger.Debugf("Limiter %s limit %d -> %d", l.opts.Name, l.limit, limit)
+			l.limit = limit
+		}
+	}
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// Token is a slot of the limiter.
+type Token struct {
+	limiter  *Limiter
+	start    time.Time
+	inflight int
+	once     sync.Once
+}
+
+// Release gives the slot back, recording the request latency.
+func (t *Token) Release() {
+	t.done(false, true)
+}
+
+// Drop gives the slot back, recording an overload.
+func (t *Token) Drop() {
+	t.done(true, true)
+}
+
+// Ignore gives the slot back without a sample, for requests that failed
+// before doing any work.
+func (t *Token) Ignore() {
+	t.done(false, false)
+}
+
+func (t *Token) done(dropped, update bool) {
+	t.once.Do(func() {
+		t.limiter.release(Sample{
+			RTT:      t.limiter.opts.Clock.Now().Sub(t.start),
+			InFlight: t.inflight,
+			Dropped:  dropped,
+		}, update)
+	})
+}
@@ -0,0 +1,125 @@
+/*  limiter_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 14:45
+ */
+
+package limiter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestFixedLimiter(t *testing.T) {
+	l := New(Opts{Name: "fixed", Algorithm: NewFixed(2)})
+
+	first, err := l.Acquire(context.Background())
+	assert.NoError(t, err)
+	_, err = l.Acquire(context.Background())
+	assert.NoError(t, err)
+	_, err = l.Acquire(context.Background())
+	assert.Equal(t, ErrLimitExceeded, err)
+	assert.Equal(t, 2, l.InFlight())
+
+	first.Release()
+	first.Release()
+	assert.Equal(t, 1, l.InFlight(), "a token is released once")
+	_, err = l.Acquire(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestLimiterWait(t *testing.T) {
+	l := New(Opts{Name: "wait", Algorithm: NewFixed(1), MaxWait: time.Second})
+	token, err := l.Acquire(context.Background())
+	assert.NoError(t, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		token.Release()
+	}()
+	next, err := l.Acquire(context.Background())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	next.Ignore()
+}
+
+func TestAIMD(t *testing.T) {
+	aimd := NewAIMD(AIMDOpts{Initial: 10, Min: 2, Max: 12, BackoffRatio: 0.5, Timeout: time.Second})
+
+	aimd.Update(Sample{RTT: time.Millisecond, InFlight: 2})
+	assert.Equal(t, 10, aimd.Limit(), "an idle limit does not grow")
+	aimd.Update(Sample{RTT: time.Millisecond, InFlight: 5})
+	aimd.Update(Sample{RTT: time.Millisecond, InFlight: 6})
+	aimd.Update(Sample{RTT: time.Millisecond, InFlight: 6})
+	assert.Equal(t, 12, aimd.Limit())
+
+	aimd.Update(Sample{RTT: time.Millisecond, InFlight: 6, Dropped: true})
+	assert.Equal(t, 6, aimd.Limit())
+	aimd.Update(Sample{RTT: 2 * time.Second, InFlight: 6})
+	assert.Equal(t, 3, aimd.Limit())
+	aimd.Update(Sample{RTT: time.Millisecond, InFlight: 3, Dropped: true})
+	assert.Equal(t, 2, aimd.Limit())
+}
+
+func TestGradient(t *testing.T) {
+	gradient := NewGradient(GradientOpts{Initial: 20, Max: 100, Smoothing: 1})
+
+	gradient.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 20})
+	assert.Equal(t, 24, gradient.Limit(), "no queueing grows the limit by sqrt(limit)")
+
+	gradient.Update(Sample{RTT: 20 * time.Millisecond, InFlight: 24})
+	assert.Equal(t, 17, gradient.Limit(), "doubled latency halves the limit before the allowance")
+
+	gradient.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 2})
+	assert.Equal(t, 17, gradient.Limit(), "an idle limiter keeps its limit")
+
+	gradient.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 17, Dropped: true})
+	assert.Equal(t, 8, gradient.Limit())
+}
+
+func TestLimiterAdapts(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	l := New(Opts{
+		Name:      "adaptive",
+		Algorithm: NewAIMD(AIMDOpts{Initial: 4, Timeout: 100 * time.Millisecond}),
+		Clock:     clock,
+	})
+
+	tokens := make([]*Token, 0, 4)
+	for i := 0; i < 4; i++ {
+		token, err := l.Acquire(context.Background())
+		assert.NoError(t, err)
+		tokens = append(tokens, token)
+	}
+	clock.Advance(200 * time.Millisecond)
+	tokens[0].Release()
+	assert.Equal(t, 3, l.Limit(), "a slow request shrinks the limit")
+	_, err := l.Acquire(context.Background())
+	assert.Equal(t, ErrLimitExceeded, err)
+}
@@ -0,0 +1,88 @@
+/*  middleware.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 14:30
+ */
+
+package limiter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/suryakencana007/mimir"
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Middleware sheds the requests over the limit with a service unavailable
+// response, it can be passed to ruuto.Router.Use. A 503 or 504 response of
+// the handler is recorded as an overload.
+func Middleware(l *Limiter) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := l.Acquire(r.Context())
+			if err != nil {
+				mimir.Response(r).APIStatusServiceUnavailableError(w, r, err).WriteJSON()
+				return
+			}
+			// a no-op once the token is given back, it only drops the
+			// token of a panicking handler
+			defer token.Drop()
+			m := httpsnoop.CaptureMetrics(next, w, r)
+			if m.Code == http.StatusServiceUnavailable || m.Code == http.StatusGatewayTimeout {
+				token.Drop()
+				return
+			}
+			token.Release()
+		})
+	}
+}
+
+// UnaryServerInterceptor sheds the calls over the limit with ResourceExhausted,
+// pass it to GRPCOpts.Opts through grpc.ChainUnaryInterceptor.
+func UnaryServerInterceptor(l *Limiter) rpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *rpc.UnaryServerInfo, handler rpc.UnaryHandler) (interface{}, error) {
+		token, err := l.Acquire(ctx)
+		if err != nil {
+			return nil, rejected(err)
+		}
+		defer token.Drop() // a no-op once the token is given back
+		resp, err := handler(ctx, req)
+		complete(token, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor sheds the streams over the limit with ResourceExhausted,
+// pass it to GRPCOpts.Opts through grpc.ChainStreamInterceptor.
+func StreamServerInterceptor(l *Limiter) rpc.StreamServerInterceptor {
+	return func(srv interface{}, ss rpc.ServerStream, info *rpc.StreamServerInfo, handler rpc.StreamHandler) error {
+		token, err := l.Acquire(ss.Context())
+		if err != nil {
+			return rejected(err)
+		}
+		defer token.Drop() // a no-op once the token is given back
+		err = handler(srv, ss)
+		complete(token, err)
+		return err
+	}
+}
+
+func rejected(err error) error {
+	if err == ErrLimitExceeded {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.FromContextError(err).Err()
+}
+
+func complete(token *Token, err error) {
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.ResourceExhausted, codes.Unavailable:
+		token.Drop()
+	default:
+		token.Release()
+	}
+}
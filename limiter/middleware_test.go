@@ -0,0 +1,130 @@
+/*  middleware_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 15:00
+ */
+
+package limiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/suryakencana007/mimir"
+	"github.com/suryakencana007/mimir/ruuto"
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMiddleware(t *testing.T) {
+	l := New(Opts{Name: "http", Algorithm: NewFixed(1)})
+	held, err := l.Acquire(context.Background())
+	assert.NoError(t, err)
+
+	router := ruuto.NewChiRouter()
+	router.Use(Middleware(l))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) {
+		mimir.Response(r).APIStatusSuccess(w, r).WriteJSON()
+	})
+
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, mimir.StatusServiceUnavailableError, w.Code)
+
+	held.Release()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Equal(t, mimir.StatusSuccess, w.Code)
+	assert.Equal(t, 0, l.InFlight())
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	l := New(Opts{Name: "grpc", Algorithm: NewAIMD(AIMDOpts{Initial: 2, BackoffRatio: 0.5})})
+	interceptor := UnaryServerInterceptor(l)
+	info := &rpc.UnaryServerInfo{FullMethod: "/booking.Booking/Create"}
+
+	var inflight int
+	resp, err := interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		inflight = l.InFlight()
+		return "resp", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+	assert.Equal(t, 1, inflight)
+
+	_, err = interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.DeadlineExceeded, "too slow")
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(t, 1, l.Limit(), "a deadline exceeded is an overload")
+
+	held, err := l.Acquire(context.Background())
+	assert.NoError(t, err)
+	defer held.Release()
+	_, err = interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("handler called over the limit")
+		return nil, nil
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestPanicDropsToken(t *testing.T) {
+	l := New(Opts{Name: "panic", Algorithm: NewFixed(1)})
+	router := ruuto.NewChiRouter()
+	router.Use(Middleware(l))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	assert.Panics(t, func() { router.ServeHTTP(httptest.NewRecorder(), r) })
+	assert.Equal(t, 0, l.InFlight())
+
+	// the recovery sees the stack of the handler panic
+	info := &rpc.UnaryServerInfo{FullMethod: "/booking.Booking/Create"}
+	stack := panicStack(func() { _, _ = UnaryServerInterceptor(l)(context.Background(), "req", info, panickingHandler) })
+	assert.Contains(t, stack, "limiter.panickingHandler(")
+	assert.Equal(t, 1, strings.Count(stack, "\npanic("), "the panic is not raised again")
+	assert.Equal(t, 0, l.InFlight())
+
+	stream := func(srv interface{}, ss rpc.ServerStream) error {
+		panic("boom")
+	}
+	assert.Panics(t, func() {
+		_ = StreamServerInterceptor(l)(nil, &serverStream{ctx: context.Background()}, &rpc.StreamServerInfo{}, stream)
+	})
+	assert.Equal(t, 0, l.InFlight())
+}
+
+func panickingHandler(ctx context.Context, req interface{}) (interface{}, error) {
+	panic("boom")
+}
+
+// panicStack returns the stack of the recovered panic of f.
+func panicStack(f func()) (stack string) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack = string(debug.Stack())
+		}
+	}()
+	f()
+	return ""
+}
+
+// serverStream is a server stream with a context only.
+type serverStream struct {
+	rpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
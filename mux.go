@@ -0,0 +1,265 @@
+/*  mux.go
+*
+* @Date:               October 19, 2026
+* @Last Modified time: 19/10/26 00:30
+ */
+
+package mimir
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/suryakencana007/mimir/ruuto"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// DefaultMuxIdleTimeout closes the idle cleartext HTTP/2 connections.
+const DefaultMuxIdleTimeout = 5 * time.Minute
+
+// MuxOpts configures ListenAndServeMux, the HTTP side is the one of
+// ServeOpts and GRPC configures the gRPC side but for its Port, Logger
+// and GracePeriod.
+//
+// TimeOut bounds the connections, the gRPC streams included, leave it
+// zero when the server has long lived streams.
+type MuxOpts struct {
+	Logger   Logging
+	Port     WebPort
+	Router   ruuto.Router
+	TimeOut  WebTimeOut
+	TLS      Https
+	CertFile string
+	KeyFile  string
+	// TLSConfig is shared by HTTP and gRPC, CertFile and KeyFile are
+	// loaded into it when they are set.
+	TLSConfig   *tls.Config
+	GracePeriod time.Duration
+	GRPC        GRPCOpts
+}
+
+// muxServer routes the HTTP/2 requests of content-type application/grpc to
+// the gRPC server and the others to the router.
+type muxServer struct {
+	router   http.Handler
+	grpc     *rpc.Server
+	health   *GRPCHealth
+	http     *http.Server
+	conns    *connTracker
+	stop     sync.Once
+	calls    int32
+	draining int32
+}
+
+// ListenAndServeMux serves HTTP/1.1, HTTP/2 and gRPC on a single port, with
+// TLS when opts.TLS is set and over h2c otherwise. The callback registers
+// the gRPC services like the one of RemoteCallProc.
+//
+// The gRPC calls go through the http.Handler of the gRPC server, which
+// lacks the keepalive and connection age options of its own transport.
+func ListenAndServeMux(opts MuxOpts) (GRPCRunFunc, func(context.Context)) {
+	logger := opts.Logger
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = DefaultGracePeriod
+	}
+	opts.GRPC.Logger = logger
+	s, hc := newGRPCServer(opts.GRPC)
+	m := &muxServer{
+		router: http.NotFoundHandler(),
+		grpc:   s,
+		health: hc,
+		conns:  &connTracker{conns: make(map[*trackedConn]struct{})},
+	}
+	if opts.Router != nil {
+		m.router = opts.Router
+	}
+
+	var handler http.Handler = m
+	if !opts.TLS {
+		handler = h2c.NewHandler(m, &http2.Server{IdleTimeout: DefaultMuxIdleTimeout})
+	}
+	m.http = &http.Server{
+		Addr:         fmt.Sprintf(":%d", opts.Port),
+		Handler:      handler,
+		ReadTimeout:  time.Duration(opts.TimeOut) * time.Second,
+		WriteTimeout: time.Duration(opts.TimeOut) * time.Second,
+		TLSConfig:    opts.TLSConfig,
+	}
+
+	cleanup := func(ctx context.Context) {
+		logger.Info("I have to go...")
+		logger.Info("Stopping server gracefully")
+		m.shutdown(ctx, logger)
+		logger.Info(fmt.Sprintf("Stop server at %s", m.http.Addr))
+	}
+
+	return func(ctx context.Context, callback GRPCCallback) error {
+		errChan := make(chan error, 1)
+		serving := listening(ctx)
+		go func() {
+			n, err := net.Listen("tcp", m.http.Addr)
+			if err != nil {
+				errChan <- err
+				return
+			}
+			if err := callback(s); err != nil {
+				_ = n.Close()
+				errChan <- err
+				return
+			}
+			m.conns.Listener = n
+			// Description µ micro service
+			fmt.Println(
+				fmt.Sprintf(
+					Welkommen(),
+					opts.Port,
+				))
+			logger.Info(fmt.Sprintf("Now serving HTTP and gRPC %v at %s", s.GetServiceInfo(), m.http.Addr))
+			serving()
+			if hc != nil {
+				watchGRPCHealth(ctx, s, hc, opts.GRPC)
+			}
+			if opts.TLS {
+				logger.Info("Secure with HTTPS")
+				errChan <- m.http.ServeTLS(m.conns, opts.CertFile, opts.KeyFile)
+			} else {
+				errChan <- m.http.Serve(m.conns)
+			}
+		}()
+
+		select {
+		case err := <-errChan:
+			if hc != nil {
+				hc.Shutdown()
+			}
+			return err
+		case <-ctx.Done():
+			// stop accepting new connections and drain the in-flight requests and calls
+			drainCtx, cancel := drainContext(ctx, opts.GracePeriod)
+			defer cancel()
+			logger.Info(fmt.Sprintf("Draining server at %s", m.http.Addr))
+			m.shutdown(drainCtx, logger)
+			return fmt.Errorf("server interrupted through context")
+		}
+	}, cleanup
+}
+
+// ServeHTTP counts the requests and the calls, the ones of the hijacked h2c
+// connections are not drained by http.Server.Shutdown.
+func (m *muxServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&m.calls, 1)
+	defer atomic.AddInt32(&m.calls, -1)
+	grpc := r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+	if atomic.LoadInt32(&m.draining) == 1 {
+		if grpc {
+			// trailers only response, the clients retry on another server
+			w.Header().Set("Content-Type", "application/grpc")
+			w.Header().Set("Grpc-Status", strconv.Itoa(int(codes.Unavailable)))
+			w.Header().Set("Grpc-Message", "server is shutting down")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Connection", "close")
+		Response(r).APIStatusServiceUnavailableError(w, r, fmt.Errorf("server is shutting down")).WriteJSON()
+		return
+	}
+	if grpc {
+		m.grpc.ServeHTTP(w, r)
+		return
+	}
+	m.router.ServeHTTP(w, r)
+}
+
+// shutdown rejects the new requests and calls, drains the in-flight ones
+// until ctx is done, then closes what is left. Only the first call drains.
+func (m *muxServer) shutdown(ctx context.Context, logger Logging) {
+	m.stop.Do(func() {
+		m.drain(ctx, logger)
+	})
+}
+
+func (m *muxServer) drain(ctx context.Context, logger Logging) {
+	atomic.StoreInt32(&m.draining, 1)
+	if m.health != nil {
+		m.health.Shutdown()
+	}
+	err := m.http.Shutdown(ctx)
+	// the h2c connections are hijacked, Shutdown neither waits for their
+	// calls nor closes them
+	if werr := m.wait(ctx); err == nil {
+		err = werr
+	}
+	m.grpc.Stop()
+	m.conns.closeAll()
+	if err != nil {
+		logger.Warnf("Drain is over due to %v, closing server", err)
+		_ = m.http.Close()
+	}
+}
+
+// wait polls the in-flight requests and calls like http.Server.Shutdown does.
+func (m *muxServer) wait(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for atomic.LoadInt32(&m.calls) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// connTracker tracks the accepted connections until they are closed, the
+// hijacked ones included.
+type connTracker struct {
+	net.Listener
+	mu    sync.Mutex
+	conns map[*trackedConn]struct{}
+}
+
+func (l *connTracker) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	tc := &trackedConn{Conn: c, tracker: l}
+	l.mu.Lock()
+	l.conns[tc] = struct{}{}
+	l.mu.Unlock()
+	return tc, nil
+}
+
+func (l *connTracker) closeAll() {
+	l.mu.Lock()
+	conns := l.conns
+	l.conns = make(map[*trackedConn]struct{})
+	l.mu.Unlock()
+	for c := range conns {
+		_ = c.Conn.Close()
+	}
+}
+
+type trackedConn struct {
+	net.Conn
+	tracker *connTracker
+}
+
+func (c *trackedConn) Close() error {
+	c.tracker.mu.Lock()
+	delete(c.tracker.conns, c)
+	c.tracker.mu.Unlock()
+	return c.Conn.Close()
+}
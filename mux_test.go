@@ -0,0 +1,206 @@
+/*  mux_test.go
+*
+* @Date:               October 19, 2026
+* @Last Modified time: 19/10/26 00:50
+ */
+
+package mimir
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suryakencana007/mimir/ruuto"
+	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// selfSignedTLS returns the server config of a localhost certificate and
+// the pool trusting it.
+func selfSignedTLS(t *testing.T) (*tls.Config, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}, pool
+}
+
+func serveMux(t *testing.T, opts MuxOpts) (port int, stop func()) {
+	port, err := findOpenPort()
+	require.NoError(t, err)
+	router := ruuto.NewChiRouter()
+	router.GET("/ping", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "pong %s", r.Proto)
+	})
+	opts.Logger, _ = newZap(t)
+	opts.Port = WebPort(port)
+	opts.Router = router
+	opts.GracePeriod = time.Second
+
+	run, _ := ListenAndServeMux(opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = run(ctx, func(*rpc.Server) error { return nil })
+	}()
+	require.NoError(t, waitForPort(port))
+	return port, func() {
+		cancel()
+		<-done
+	}
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestListenAndServeMux(t *testing.T) {
+	port, stop := serveMux(t, MuxOpts{})
+
+	assert.Equal(t, "pong HTTP/1.1", get(t, http.DefaultClient, fmt.Sprintf("http://localhost:%d/ping", port)))
+
+	conn, err := rpc.Dial(fmt.Sprintf("localhost:%d", port), rpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, client, ""))
+
+	// the calls are rejected once the server drains and the hijacked h2c
+	// connection is closed
+	stop()
+	assert.Eventually(t, func() bool {
+		return conn.GetState() != connectivity.Ready
+	}, time.Second, time.Millisecond)
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Error(t, err)
+	_, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	assert.Error(t, err)
+}
+
+func TestListenAndServeMuxTLS(t *testing.T) {
+	config, pool := selfSignedTLS(t)
+	port, stop := serveMux(t, MuxOpts{TLS: true, TLSConfig: config})
+	defer stop()
+
+	url := fmt.Sprintf("https://localhost:%d/ping", port)
+	h1 := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	assert.Equal(t, "pong HTTP/1.1", get(t, h1, url))
+	h2 := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}, ForceAttemptHTTP2: true}}
+	assert.Equal(t, "pong HTTP/2.0", get(t, h2, url))
+
+	conn, err := rpc.Dial(fmt.Sprintf("localhost:%d", port),
+		rpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool})))
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, healthpb.NewHealthClient(conn), ""))
+}
+
+func TestListenAndServeMuxDraining(t *testing.T) {
+	port, err := findOpenPort()
+	require.NoError(t, err)
+	inflight, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	router := ruuto.NewChiRouter()
+	router.GET("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(inflight)
+		<-release
+	})
+	logger, spy := newZap(t)
+	run, cleanup := ListenAndServeMux(MuxOpts{
+		Logger:      logger,
+		Port:        WebPort(port),
+		Router:      router,
+		GracePeriod: 50 * time.Millisecond,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = run(ctx, func(*rpc.Server) error { return nil })
+	}()
+	require.NoError(t, waitForPort(port))
+	go func() {
+		resp, err := http.Get(fmt.Sprintf("http://localhost:%d/slow", port))
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+	<-inflight
+
+	// the cleanup does not drain a drained server again
+	cancel()
+	<-done
+	cleanupCtx, cancelCleanup := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelCleanup()
+	cleanup(cleanupCtx)
+	drains := 0
+	for _, msg := range spy.Messages {
+		if strings.Contains(msg, "Drain is over") {
+			drains++
+		}
+	}
+	assert.Equal(t, 1, drains)
+}
+
+func TestMuxServerRejectsWhileDraining(t *testing.T) {
+	m := &muxServer{router: http.NotFoundHandler(), grpc: rpc.NewServer(), draining: 1}
+
+	// the requests of the hijacked h2c connections are rejected too
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	r.ProtoMajor = 2
+	m.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/grpc.health.v1.Health/Check", nil)
+	r.ProtoMajor = 2
+	r.Header.Set("Content-Type", "application/grpc")
+	m.ServeHTTP(w, r)
+	assert.Equal(t, strconv.Itoa(int(codes.Unavailable)), w.Header().Get("Grpc-Status"))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&m.calls))
+}
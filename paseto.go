@@ -0,0 +1,201 @@
+/*  paseto.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 18:40
+ */
+
+package mimir
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	pasetoLocalHeader  = "v2.local."
+	pasetoPublicHeader = "v2.public."
+)
+
+// PasetoOpts configures the PASETO v2 tokens, LocalKey is the 32 bytes key
+// of the local tokens, PrivateKey signs and PublicKey verifies the public
+// tokens. Footer is appended to the issued tokens and, when set, required
+// on the verified ones.
+type PasetoOpts struct {
+	LocalKey   []byte
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+	Footer     string
+	Claims     ClaimsOpts
+}
+
+// Paseto issues and verifies PASETO v2 tokens, local tokens are encrypted
+// with XChaCha20-Poly1305 and public tokens signed with Ed25519.
+type Paseto struct {
+	opts PasetoOpts
+}
+
+func NewPaseto(opts PasetoOpts) *Paseto {
+	if opts.PublicKey == nil && opts.PrivateKey != nil {
+		opts.PublicKey = opts.PrivateKey.Public().(ed25519.PublicKey)
+	}
+	return &Paseto{opts: opts}
+}
+
+// Issue makes a local token when the local key is set, else a public one.
+func (p *Paseto) Issue(claims Claims) (string, error) {
+	payload, err := json.Marshal(claims.toMap(false))
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case p.opts.LocalKey != nil:
+		return p.encrypt(payload)
+	case p.opts.PrivateKey != nil:
+		return p.sign(payload), nil
+	}
+	return "", fmt.Errorf("paseto: no local or private key")
+}
+
+func (p *Paseto) Verify(token string) (*Claims, error) {
+	var (
+		payload []byte
+		err     error
+	)
+	switch {
+	case strings.HasPrefix(token, pasetoLocalHeader) && p.opts.LocalKey != nil:
+		payload, err = p.decrypt(token)
+	case strings.HasPrefix(token, pasetoPublicHeader) && p.opts.PublicKey != nil:
+		payload, err = p.open(token)
+	default:
+		return nil, ErrTokenUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	claims, err := claimsFromJSON(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := claims.Validate(p.opts.Claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (p *Paseto) encrypt(payload []byte) (string, error) {
+	aead, err := chacha20poly1305.NewX(p.opts.LocalKey)
+	if err != nil {
+		return "", err
+	}
+	random := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := io.ReadFull(rand.Reader, random); err != nil {
+		return "", err
+	}
+	// the nonce is derived from the payload so a weak random
+	// source cannot repeat it
+	mac, err := blake2b.New(chacha20poly1305.NonceSizeX, random)
+	if err != nil {
+		return "", err
+	}
+	_, _ = mac.Write(payload)
+	nonce := mac.Sum(nil)
+
+	footer := []byte(p.opts.Footer)
+	sealed := aead.Seal(nil, nonce, payload, pae([]byte(pasetoLocalHeader), nonce, footer))
+	return pasetoToken(pasetoLocalHeader, append(nonce, sealed...), footer), nil
+}
+
+func (p *Paseto) decrypt(token string) ([]byte, error) {
+	body, footer, err := p.split(token, pasetoLocalHeader)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(p.opts.LocalKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < chacha20poly1305.NonceSizeX+aead.Overhead() {
+		return nil, ErrTokenMalformed
+	}
+	nonce, sealed := body[:chacha20poly1305.NonceSizeX], body[chacha20poly1305.NonceSizeX:]
+	payload, err := aead.Open(nil, nonce, sealed, pae([]byte(pasetoLocalHeader), nonce, footer))
+	if err != nil {
+		return nil, ErrTokenSignature
+	}
+	return payload, nil
+}
+
+func (p *Paseto) sign(payload []byte) string {
+	footer := []byte(p.opts.Footer)
+	sig := ed25519.Sign(p.opts.PrivateKey, pae([]byte(pasetoPublicHeader), payload, footer))
+	return pasetoToken(pasetoPublicHeader, append(payload, sig...), footer)
+}
+
+func (p *Paseto) open(token string) ([]byte, error) {
+	body, footer, err := p.split(token, pasetoPublicHeader)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < ed25519.SignatureSize {
+		return nil, ErrTokenMalformed
+	}
+	payload, sig := body[:len(body)-ed25519.SignatureSize], body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(p.opts.PublicKey, pae([]byte(pasetoPublicHeader), payload, footer), sig) {
+		return nil, ErrTokenSignature
+	}
+	return payload, nil
+}
+
+// split decodes the body and the footer of a token.
+func (p *Paseto) split(token, header string) ([]byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(token, header), ".")
+	if len(parts) > 2 {
+		return nil, nil, ErrTokenMalformed
+	}
+	body, err := b64url.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, ErrTokenMalformed
+	}
+	var footer []byte
+	if len(parts) == 2 {
+		if footer, err = b64url.DecodeString(parts[1]); err != nil {
+			return nil, nil, ErrTokenMalformed
+		}
+	}
+	if p.opts.Footer != "" && subtle.ConstantTimeCompare(footer, []byte(p.opts.Footer)) != 1 {
+		return nil, nil, ErrTokenSignature
+	}
+	return body, footer, nil
+}
+
+func pasetoToken(header string, body, footer []byte) string {
+	token := header + b64url.EncodeToString(body)
+	if len(footer) > 0 {
+		token += "." + b64url.EncodeToString(footer)
+	}
+	return token
+}
+
+// pae is the pre-authentication encoding of PASETO.
+func pae(pieces ...[]byte) []byte {
+	le64 := func(n int) []byte {
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, uint64(n)&^(1<<63))
+		return b
+	}
+	out := le64(len(pieces))
+	for _, piece := range pieces {
+		out = append(out, le64(len(piece))...)
+		out = append(out, piece...)
+	}
+	return out
+}
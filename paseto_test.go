@@ -0,0 +1,82 @@
+/*  paseto_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 19:40
+ */
+
+package mimir
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasetoLocal(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	clock := newFakeClock()
+
+	p := NewPaseto(PasetoOpts{LocalKey: key, Footer: "kid-1", Claims: ClaimsOpts{Clock: clock}})
+	token, err := p.Issue(Claims{Subject: "42", ExpiresAt: clock.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "v2.local."))
+	assert.True(t, strings.HasSuffix(token, "."+b64url.EncodeToString([]byte("kid-1"))))
+
+	claims, err := p.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "42", claims.Subject)
+
+	other := NewPaseto(PasetoOpts{LocalKey: make([]byte, 32), Claims: ClaimsOpts{Clock: clock}})
+	_, err = other.Verify(token)
+	assert.Equal(t, ErrTokenSignature, err)
+
+	// the footer is authenticated
+	forged := token[:strings.LastIndex(token, ".")+1] + b64url.EncodeToString([]byte("kid-2"))
+	_, err = NewPaseto(PasetoOpts{LocalKey: key, Claims: ClaimsOpts{Clock: clock}}).Verify(forged)
+	assert.Equal(t, ErrTokenSignature, err)
+
+	clock.Advance(2 * time.Hour)
+	_, err = p.Verify(token)
+	assert.Equal(t, ErrTokenExpired, err)
+}
+
+func TestPasetoPublic(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	issuer := NewPaseto(PasetoOpts{PrivateKey: private})
+	token, err := issuer.Issue(Claims{Issuer: "mimir", Subject: "42"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "v2.public."))
+
+	verifier := NewPaseto(PasetoOpts{PublicKey: public, Claims: ClaimsOpts{Issuer: "mimir"}})
+	claims, err := verifier.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "42", claims.Subject)
+
+	_, err = verifier.Verify(strings.Replace(token, "v2.public.", "v2.local.", 1))
+	assert.Equal(t, ErrTokenUnsupported, err)
+}
+
+func TestPasetoPublicVector(t *testing.T) {
+	// test vector of the PASETO v2 specification
+	public, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+	token := "v2.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAxOS0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+		"HQr8URrGntTu7Dz9J2IF23d1M7-9lH9xiqdGyJNvzp4angPW5Esc7C5huy_M8I8_DjJK2ZXC2SUYuOFM-Q_5Cw"
+
+	clock := &fakeClock{now: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := NewPaseto(PasetoOpts{PublicKey: ed25519.PublicKey(public), Claims: ClaimsOpts{Clock: clock}})
+	claims, err := p.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "this is a signed message", claims.Extra["data"])
+	assert.Equal(t, time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC).Unix(), claims.ExpiresAt.Unix())
+}
@@ -0,0 +1,132 @@
+/*  readiness.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 10:05
+ */
+
+package mimir
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type ctxKeyReadiness struct {
+	Name string
+}
+
+func (r *ctxKeyReadiness) String() string {
+	return "context value " + r.Name
+}
+
+var CtxReadiness = ctxKeyReadiness{Name: "context readiness"}
+
+// ReadinessChecker reports whether the application accepts new work.
+type ReadinessChecker interface {
+	IsReady() bool
+}
+
+// Readiness is a concurrency safe readiness flag. It turns ready once the
+// listeners of the application listen and is flipped to false as soon as
+// the application starts draining.
+type Readiness struct {
+	mu       sync.Mutex
+	ready    bool
+	pending  int
+	draining bool
+	deadline time.Time
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+func (r *Readiness) SetReady(ready bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ready = ready
+}
+
+func (r *Readiness) IsReady() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ready && r.pending == 0
+}
+
+// Listening registers a listener starting up, the readiness turns ready
+// once every registered listener called the returned func.
+func (r *Readiness) Listening() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.pending--
+			if r.pending == 0 && !r.draining {
+				r.ready = true
+			}
+		})
+	}
+}
+
+// drain flips the readiness to false for good, the servers drain their
+// in-flight work until deadline when it is set.
+func (r *Readiness) drain(deadline time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.draining = true
+	r.ready = false
+	r.deadline = deadline
+}
+
+func (r *Readiness) drainDeadline() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deadline
+}
+
+// ReadinessFrom returns the Readiness of the running Application, nil when
+// the context was not created by Application.
+func ReadinessFrom(ctx context.Context) *Readiness {
+	if r, ok := ctx.Value(CtxReadiness).(*Readiness); ok {
+		return r
+	}
+	return nil
+}
+
+// listening registers a listener on the Readiness of ctx, see Readiness.Listening.
+func listening(ctx context.Context) func() {
+	if r := ReadinessFrom(ctx); r != nil {
+		return r.Listening()
+	}
+	return func() {}
+}
+
+// drainContext bounds the drain of a server by the grace period of the
+// Application of ctx, or by grace when ctx was not created by Application.
+func drainContext(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	if r := ReadinessFrom(ctx); r != nil {
+		if deadline := r.drainDeadline(); !deadline.IsZero() {
+			return context.WithDeadline(context.Background(), deadline)
+		}
+	}
+	return context.WithTimeout(context.Background(), grace)
+}
+
+// ReadinessHandler serves the readiness state for load balancer and kubernetes probes.
+func ReadinessHandler(check ReadinessChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := Response(r)
+		if check == nil || !check.IsReady() {
+			resp.APIStatusServiceUnavailableError(w, r, fmt.Errorf("not ready")).WriteJSON()
+			return
+		}
+		resp.APIStatusSuccess(w, r).WriteJSON()
+	}
+}
@@ -0,0 +1,84 @@
+/*  readiness_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 10:40
+ */
+
+package mimir
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suryakencana007/mimir/ruuto"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	readiness := NewReadiness()
+	handler := ReadinessHandler(readiness)
+
+	r, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	handler(w, r)
+	assert.Equal(t, StatusServiceUnavailableError, w.Code)
+
+	readiness.SetReady(true)
+	r, err = http.NewRequest(http.MethodGet, "/readyz", nil)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	handler(w, r)
+	assert.Equal(t, StatusSuccess, w.Code)
+}
+
+func TestReadinessListening(t *testing.T) {
+	readiness := NewReadiness()
+	web, remote := readiness.Listening(), readiness.Listening()
+	assert.False(t, readiness.IsReady())
+	web()
+	web()
+	assert.False(t, readiness.IsReady())
+	remote()
+	assert.True(t, readiness.IsReady())
+
+	// a listener does not make a draining application ready again
+	readiness.drain(time.Time{})
+	readiness.Listening()()
+	assert.False(t, readiness.IsReady())
+}
+
+func TestListenAndServeReadiness(t *testing.T) {
+	port, err := findOpenPort()
+	require.NoError(t, err)
+	readiness := NewReadiness()
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), CtxReadiness, readiness))
+
+	run, _ := ListenAndServe(ServeOpts{
+		Logger:      With(Field("testing", "TestListenAndServeReadiness")),
+		Port:        WebPort(port),
+		Router:      ruuto.NewChiRouter(),
+		GracePeriod: time.Second,
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// the port accepts connections once it reports ready
+	assert.Eventually(t, readiness.IsReady, time.Second, time.Millisecond)
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	require.NoError(t, err)
+	_ = conn.Close()
+}
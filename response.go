@@ -10,6 +10,7 @@ package mimir
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -171,6 +172,16 @@ func (r *Respond) APIStatusForbidden(w http.ResponseWriter, req *http.Request, e
 	return Status(w, req, StatusForbidden, r)
 }
 
+// APIStatusNotFound
+func (r *Respond) APIStatusNotFound(w http.ResponseWriter, req *http.Request, err error) *responseWriter {
+	r.Errors(Meta{
+		Code:    strconv.Itoa(StatusNotFound),
+		Type:    StatusCode(StatusNotFound),
+		Message: fmt.Sprintf("%s or %v", StatusText(StatusNotFound), err.Error()),
+	})
+	return Status(w, req, StatusNotFound, r)
+}
+
 // APIStatusMethodNotAllowed
 func (r *Respond) APIStatusMethodNotAllowed(w http.ResponseWriter, req *http.Request, err error) *responseWriter {
 	r.Errors(Meta{
@@ -221,6 +232,16 @@ func (r *Respond) APIStatusUnsupportedMediaType(w http.ResponseWriter, req *http
 	return Status(w, req, StatusUnsupportedMediaType, r)
 }
 
+// APIStatusConflict
+func (r *Respond) APIStatusConflict(w http.ResponseWriter, req *http.Request, err error) *responseWriter {
+	r.Errors(Meta{
+		Code:    strconv.Itoa(StatusConflict),
+		Type:    StatusCode(StatusConflict),
+		Message: fmt.Sprintf("%s or %v", StatusText(StatusConflict), err.Error()),
+	})
+	return Status(w, req, StatusConflict, r)
+}
+
 // APIStatusUnProcess
 func (r *Respond) APIStatusUnProcess(w http.ResponseWriter, req *http.Request, err error) *responseWriter {
 	r.Errors(Meta{
@@ -231,6 +252,16 @@ func (r *Respond) APIStatusUnProcess(w http.ResponseWriter, req *http.Request, e
 	return Status(w, req, StatusUnProcess, r)
 }
 
+// APIStatusTooManyRequests
+func (r *Respond) APIStatusTooManyRequests(w http.ResponseWriter, req *http.Request, err error) *responseWriter {
+	r.Errors(Meta{
+		Code:    strconv.Itoa(StatusTooManyRequests),
+		Type:    StatusCode(StatusTooManyRequests),
+		Message: fmt.Sprintf("%s or %v", StatusText(StatusTooManyRequests), err.Error()),
+	})
+	return Status(w, req, StatusTooManyRequests, r)
+}
+
 // APIStatusInternalError
 func (r *Respond) APIStatusInternalError(w http.ResponseWriter, req *http.Request, err error) *responseWriter {
 	r.Errors(Meta{
@@ -241,6 +272,16 @@ func (r *Respond) APIStatusInternalError(w http.ResponseWriter, req *http.Reques
 	return Status(w, req, StatusInternalError, r)
 }
 
+// APIStatusNotImplementedError
+func (r *Respond) APIStatusNotImplementedError(w http.ResponseWriter, req *http.Request, err error) *responseWriter {
+	r.Errors(Meta{
+		Code:    strconv.Itoa(StatusNotImplementedError),
+		Type:    StatusCode(StatusNotImplementedError),
+		Message: fmt.Sprintf("%s or %v", StatusText(StatusNotImplementedError), err.Error()),
+	})
+	return Status(w, req, StatusNotImplementedError, r)
+}
+
 // APIStatusBadGatewayError
 func (r *Respond) APIStatusBadGatewayError(w http.ResponseWriter, req *http.Request, err error) *responseWriter {
 	r.Errors(Meta{
@@ -271,6 +312,22 @@ func (r *Respond) APIStatusGatewayTimeoutError(w http.ResponseWriter, req *http.
 	return Status(w, req, StatusGatewayTimeoutError, r)
 }
 
+// APIStatusError maps the error to its status, a DomainError or a gRPC
+// status error to the status of its code, the breaker rejections to
+// service unavailable and the timeouts to gateway timeout.
+func (r *Respond) APIStatusError(w http.ResponseWriter, req *http.Request, err error) *responseWriter {
+	if e, ok := AsDomainError(err); ok {
+		return r.writeError(w, req, e)
+	}
+	switch {
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrMaxConcurrency):
+		return r.APIStatusServiceUnavailableError(w, req, err)
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return r.APIStatusGatewayTimeoutError(w, req, err)
+	}
+	return r.APIStatusInternalError(w, req, err)
+}
+
 type responseWriter struct {
 	Request  *http.Request
 	Writer   http.ResponseWriter
@@ -9,6 +9,7 @@
 package mimir
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -167,3 +168,23 @@ SO45645,WPD003343,Jakarta Selatan,1,43.00,3
 	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
 
 }
+
+func TestResponseAPIStatusError(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{ErrCircuitOpen, StatusServiceUnavailableError},
+		{fmt.Errorf("payment: %w", ErrMaxConcurrency), StatusServiceUnavailableError},
+		{ErrTimeout, StatusGatewayTimeoutError},
+		{context.DeadlineExceeded, StatusGatewayTimeoutError},
+		{fmt.Errorf("constraint unique key duplicate"), StatusInternalError},
+	}
+	for _, c := range cases {
+		r, err := http.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		Response(r).APIStatusError(w, r, c.err).WriteJSON()
+		assert.Equal(t, c.code, w.Code, c.err.Error())
+	}
+}
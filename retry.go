@@ -0,0 +1,170 @@
+/*  retry.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 13:05
+ */
+
+package mimir
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultRetryAttempts = 3
+	DefaultRetryBase     = 100 * time.Millisecond
+	DefaultRetryMax      = 10 * time.Second
+)
+
+type (
+	// BackoffFunc returns the delay before the next attempt, attempt starts
+	// at 1 and last is the previous delay, zero before the first retry.
+	BackoffFunc func(attempt int, last time.Duration) time.Duration
+
+	// Sleeper waits for the delay or until the context is done.
+	Sleeper func(ctx context.Context, d time.Duration) error
+)
+
+// Retry is a retry policy. The zero value retries 3 times with an
+// exponential backoff.
+//
+// Calls go through Breaker when it is set, an open circuit stops the
+// retries at once so they never hammer an unhealthy dependency.
+type Retry struct {
+	MaxAttempts int
+	// MaxElapsed stops retrying when the next delay would exceed it,
+	// zero means no limit.
+	MaxElapsed time.Duration
+	Backoff    BackoffFunc
+	// Retryable classifies the errors worth another attempt,
+	// DefaultRetryable is used when it is nil.
+	Retryable func(error) bool
+	Breaker   *CircuitBreaker
+	Clock     Clock
+	Sleep     Sleeper
+}
+
+// Do calls fn until it succeeds, the error is not retryable, the attempts
+// or the elapsed time are exhausted or ctx is done. It returns the last
+// error of fn, or the context error when ctx ended a backoff.
+func (r Retry) Do(ctx context.Context, fn func(context.Context) error) error {
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = DefaultRetryAttempts
+	}
+	if r.Backoff == nil {
+		r.Backoff = ExponentialBackoff(DefaultRetryBase, DefaultRetryMax)
+	}
+	if r.Retryable == nil {
+		r.Retryable = DefaultRetryable
+	}
+	if r.Clock == nil {
+		r.Clock = SystemClock
+	}
+	if r.Sleep == nil {
+		r.Sleep = SleepContext
+	}
+
+	start := r.Clock.Now()
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		err := r.call(ctx, fn)
+		if err == nil {
+			return nil
+		}
+		if attempt >= r.MaxAttempts || errors.Is(err, ErrCircuitOpen) || !r.Retryable(err) {
+			return err
+		}
+
+		delay = r.Backoff(attempt, delay)
+		if r.MaxElapsed > 0 && r.Clock.Now().Sub(start)+delay > r.MaxElapsed {
+			return err
+		}
+		if err := r.Sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (r Retry) call(ctx context.Context, fn func(context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.Breaker != nil {
+		return r.Breaker.ExecuteContext(ctx, fn)
+	}
+	return fn(ctx)
+}
+
+// DefaultRetryable retries every error but an open circuit
+// and a cancelled or expired context.
+func DefaultRetryable(err error) bool {
+	return !errors.Is(err, ErrCircuitOpen) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
+
+// SleepContext waits for d or until ctx is done.
+func SleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// ConstantBackoff waits d between the attempts.
+func ConstantBackoff(d time.Duration) BackoffFunc {
+	return func(int, time.Duration) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff doubles the delay from base on every attempt up to max.
+func ExponentialBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempt int, _ time.Duration) time.Duration {
+		delay := base
+		for i := 1; i < attempt; i++ {
+			delay *= 2
+			if delay >= max || delay <= 0 {
+				return max
+			}
+		}
+		if delay > max {
+			return max
+		}
+		return delay
+	}
+}
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// DecorrelatedJitterBackoff picks a random delay between base and three
+// times the last delay, capped at max.
+func DecorrelatedJitterBackoff(base, max time.Duration) BackoffFunc {
+	return func(_ int, last time.Duration) time.Duration {
+		if last < base {
+			last = base
+		}
+		upper := last * 3
+		if upper > max || upper <= 0 {
+			upper = max
+		}
+		if upper <= base {
+			return upper
+		}
+		jitterMu.Lock()
+		delay := base + time.Duration(jitterRand.Int63n(int64(upper-base)))
+		jitterMu.Unlock()
+		return delay
+	}
+}
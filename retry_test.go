@@ -0,0 +1,136 @@
+/*  retry_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 13:30
+ */
+
+package mimir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordSleeper advances the clock instead of sleeping.
+func recordSleeper(clock *fakeClock, delays *[]time.Duration) Sleeper {
+	return func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		clock.Advance(d)
+		return ctx.Err()
+	}
+}
+
+func TestRetryExponential(t *testing.T) {
+	clock := newFakeClock()
+	var delays []time.Duration
+	calls := 0
+	err := Retry{
+		MaxAttempts: 5,
+		Backoff:     ExponentialBackoff(100*time.Millisecond, 300*time.Millisecond),
+		Clock:       clock,
+		Sleep:       recordSleeper(clock, &delays),
+	}.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 5 {
+			return errBackend
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, calls)
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond,
+	}, delays)
+}
+
+func TestRetryExhausted(t *testing.T) {
+	clock := newFakeClock()
+	var delays []time.Duration
+	calls := 0
+	err := Retry{
+		MaxAttempts: 10,
+		MaxElapsed:  time.Second,
+		Backoff:     ConstantBackoff(400 * time.Millisecond),
+		Clock:       clock,
+		Sleep:       recordSleeper(clock, &delays),
+	}.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return fmt.Errorf("attempt %d: %w", calls, errBackend)
+	})
+
+	assert.EqualError(t, err, "attempt 3: backend unavailable")
+	assert.True(t, errors.Is(err, errBackend))
+	assert.Len(t, delays, 2, "the third delay would exceed the max elapsed time")
+}
+
+func TestRetryClassifier(t *testing.T) {
+	errInvalid := errors.New("invalid argument")
+	calls := 0
+	err := Retry{
+		Retryable: func(err error) bool { return !errors.Is(err, errInvalid) },
+		Sleep:     func(context.Context, time.Duration) error { return nil },
+	}.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errInvalid
+	})
+	assert.Equal(t, errInvalid, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Retry{
+		MaxAttempts: 5,
+		Backoff:     ConstantBackoff(time.Hour),
+	}.Do(ctx, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errBackend
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryOpenCircuit(t *testing.T) {
+	clock := newFakeClock()
+	cb := NewCircuitBreaker(BreakerOpts{
+		Name:                "retry",
+		ConsecutiveFailures: 2,
+		Clock:               clock,
+	})
+	var delays []time.Duration
+	calls := 0
+	err := Retry{
+		MaxAttempts: 5,
+		Breaker:     cb,
+		Clock:       clock,
+		Sleep:       recordSleeper(clock, &delays),
+	}.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errBackend
+	})
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, 2, calls)
+	assert.Len(t, delays, 2)
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	backoff := DecorrelatedJitterBackoff(10*time.Millisecond, time.Second)
+	var last time.Duration
+	for attempt := 1; attempt <= 50; attempt++ {
+		delay := backoff(attempt, last)
+		assert.True(t, delay >= 10*time.Millisecond, "delay %s below base", delay)
+		assert.True(t, delay <= time.Second, "delay %s above max", delay)
+		if last >= 10*time.Millisecond {
+			assert.True(t, delay <= 3*last, "delay %s above three times %s", delay, last)
+		}
+		last = delay
+	}
+}
@@ -0,0 +1,130 @@
+/*  secret.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 13:05
+ */
+
+package mimir
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const Redacted = "******"
+
+// secretReference matches ${provider:reference} inside a configuration value.
+var secretReference = regexp.MustCompile(`\$\{([a-zA-Z0-9_-]+):([^}]*)\}`)
+
+// SecretProvider resolves the reference part of a ${provider:reference} value.
+type SecretProvider interface {
+	Secret(ref string) (string, error)
+}
+
+type SecretProviderFunc func(ref string) (string, error)
+
+func (f SecretProviderFunc) Secret(ref string) (string, error) {
+	return f(ref)
+}
+
+// FileSecretProvider reads the secret from a file, relative references are
+// looked up in Dir. The trailing newline of the file is dropped.
+type FileSecretProvider struct {
+	Dir string
+}
+
+func (p FileSecretProvider) Secret(ref string) (string, error) {
+	if !filepath.IsAbs(ref) && p.Dir != "" {
+		ref = filepath.Join(p.Dir, ref)
+	}
+	b, err := ioutil.ReadFile(filepath.Clean(ref))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// EnvSecretProvider reads the secret from an environment variable.
+type EnvSecretProvider struct{}
+
+func (p EnvSecretProvider) Secret(ref string) (string, error) {
+	val, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	}
+	return val, nil
+}
+
+// Secret is a string configuration value which is redacted when printed,
+// logged or marshalled.
+type Secret string
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	return Redacted
+}
+
+func (s Secret) GoString() string {
+	return Redacted
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + Redacted + `"`), nil
+}
+
+func secretProviders(custom map[string]SecretProvider) map[string]SecretProvider {
+	providers := map[string]SecretProvider{
+		"file": FileSecretProvider{},
+		"env":  EnvSecretProvider{},
+	}
+	for name, provider := range custom {
+		providers[name] = provider
+	}
+	return providers
+}
+
+// resolveSecrets replaces every secret reference of the merged configuration
+// and returns the keys holding a resolved secret.
+func resolveSecrets(v *viper.Viper, providers map[string]SecretProvider) (map[string]bool, error) {
+	secrets := make(map[string]bool)
+	for _, key := range v.AllKeys() {
+		val, ok := v.Get(key).(string)
+		if !ok || !secretReference.MatchString(val) {
+			continue
+		}
+		var err error
+		resolved := secretReference.ReplaceAllStringFunc(val, func(ref string) string {
+			match := secretReference.FindStringSubmatch(ref)
+			provider, ok := providers[match[1]]
+			if !ok {
+				err = fmt.Errorf("config %s: unknown secret provider %q", key, match[1])
+				return ref
+			}
+			secret, e := provider.Secret(match[2])
+			if e != nil {
+				err = fmt.Errorf("config %s: %s secret: %v", key, match[1], e)
+				return ref
+			}
+			return secret
+		})
+		if err != nil {
+			return nil, err
+		}
+		v.Set(key, resolved)
+		secrets[key] = true
+	}
+	return secrets, nil
+}
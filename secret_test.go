@@ -0,0 +1,114 @@
+/*  secret_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 13:30
+ */
+
+package mimir
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type secretConfig struct {
+	App struct {
+		Name      string `mapstructure:"name"`
+		SecretKey Secret `mapstructure:"secret_key"`
+	}
+	DB struct {
+		DsnMain string `mapstructure:"dsn_main"`
+		Token   Secret `mapstructure:"token"`
+	}
+}
+
+func TestConfigSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimir-secret")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	secretFile := filepath.Join(dir, "db_password")
+	assert.NoError(t, ioutil.WriteFile(secretFile, []byte("root123\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.config.yaml"), []byte(fmt.Sprintf(
+		"App:\n  name: laugh-tale\n  secret_key: ${env:MIMIR_TEST_SECRET_KEY}\n"+
+			"DB:\n  dsn_main: host=localhost password=${file:%s}\n  token: ${vault:db/token}\n",
+		secretFile,
+	)), 0600))
+
+	_ = os.Setenv("MIMIR_TEST_SECRET_KEY", "sekret")
+	defer os.Unsetenv("MIMIR_TEST_SECRET_KEY")
+
+	cfg := &secretConfig{}
+	opts := ConfigOpts{
+		Config:   cfg,
+		Filename: "app.config",
+		Paths:    []string{dir},
+		SecretProviders: map[string]SecretProvider{
+			"vault": SecretProviderFunc(func(ref string) (string, error) {
+				if ref != "db/token" {
+					return "", fmt.Errorf("secret %s not found", ref)
+				}
+				return "t0k3n", nil
+			}),
+		},
+	}
+	source, err := loadConfig(opts, func(v *viper.Viper) error {
+		return nil
+	}, cfg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "laugh-tale", cfg.App.Name)
+	assert.Equal(t, "sekret", cfg.App.SecretKey.Value())
+	assert.Equal(t, "host=localhost password=root123", cfg.DB.DsnMain)
+	assert.Equal(t, "t0k3n", cfg.DB.Token.Value())
+
+	// secrets never leak when dumped
+	settings := source.settings()
+	assert.Equal(t, Redacted, settings["app.secret_key"])
+	assert.Equal(t, Redacted, settings["db.dsn_main"])
+	assert.Equal(t, "laugh-tale", settings["app.name"])
+
+	b, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "sekret")
+	assert.NotContains(t, fmt.Sprintf("%v %+v %#v", cfg.App.SecretKey, cfg, cfg.DB.Token), "t0k3n")
+}
+
+func TestConfigSecretsUnknownProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimir-secret")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.config.yaml"),
+		[]byte("App:\n  secret_key: ${vault:app/key}\n"), 0600))
+
+	cfg := &secretConfig{}
+	err = Config(ConfigOpts{
+		Config:   cfg,
+		Filename: "app.config",
+		Paths:    []string{dir},
+	}, func(v *viper.Viper) error {
+		return nil
+	})
+	assert.EqualError(t, err, `config app.secret_key: unknown secret provider "vault"`)
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimir-secret")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "api_key"), []byte("k3y\r\n"), 0600))
+
+	secret, err := FileSecretProvider{Dir: dir}.Secret("api_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "k3y", secret)
+
+	_, err = FileSecretProvider{Dir: dir}.Secret("missing")
+	assert.Error(t, err)
+}
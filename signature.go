@@ -0,0 +1,270 @@
+/*  signature.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 20:30
+ */
+
+package mimir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderSignature          = "X-Signature"
+	HeaderSignatureKeyID     = "X-Signature-Key-Id"
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderSignatureNonce     = "X-Signature-Nonce"
+
+	DefaultSignatureSkew    = 5 * time.Minute
+	DefaultSignatureMaxBody = 10 << 20
+)
+
+var (
+	ErrSignatureMissing    = errors.New("request signature is missing")
+	ErrSignatureInvalid    = errors.New("request signature is invalid")
+	ErrSignatureExpired    = errors.New("request signature timestamp is out of range")
+	ErrSignatureReplayed   = errors.New("request signature nonce was already used")
+	ErrSignatureUnknownKey = errors.New("request signature key is unknown")
+	ErrSignatureBodyTooBig = errors.New("request body is too large to verify")
+)
+
+type (
+	// NonceCache remembers the nonces of the verified requests,
+	// Seen reports whether nonce was seen before and remembers it
+	// until expires otherwise.
+	NonceCache interface {
+		Seen(nonce string, expires time.Time) bool
+	}
+
+	// RequestSigner signs requests with an HMAC-SHA256 of their method,
+	// path, timestamp, nonce and body digest.
+	RequestSigner struct {
+		KeyID  string
+		Secret []byte
+		Clock  Clock
+	}
+
+	// SignatureVerifierOpts configures a SignatureVerifier, Keys maps the key
+	// ids to their secrets, the requests without key id are checked with the
+	// "" key. Skew bounds the age of the timestamp in both directions.
+	SignatureVerifierOpts struct {
+		Keys    map[string][]byte
+		Skew    time.Duration
+		Nonces  NonceCache
+		MaxBody int64
+		Clock   Clock
+	}
+
+	SignatureVerifier struct {
+		opts SignatureVerifierOpts
+	}
+)
+
+// Sign sets the signature headers of req, its body is read and replaced.
+func (s *RequestSigner) Sign(req *http.Request) error {
+	body, err := readBody(req, -1)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	clock := s.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	timestamp := strconv.FormatInt(clock.Now().Unix(), 10)
+	req.Header.Set(HeaderSignatureTimestamp, timestamp)
+	req.Header.Set(HeaderSignatureNonce, hex.EncodeToString(nonce))
+	if s.KeyID != "" {
+		req.Header.Set(HeaderSignatureKeyID, s.KeyID)
+	}
+	mac := HMACSHA256(s.Secret, canonicalRequest(req, timestamp, req.Header.Get(HeaderSignatureNonce), body))
+	req.Header.Set(HeaderSignature, hex.EncodeToString(mac))
+	return nil
+}
+
+func NewSignatureVerifier(opts SignatureVerifierOpts) *SignatureVerifier {
+	if opts.Skew <= 0 {
+		opts.Skew = DefaultSignatureSkew
+	}
+	if opts.MaxBody <= 0 {
+		opts.MaxBody = DefaultSignatureMaxBody
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+	if opts.Nonces == nil {
+		opts.Nonces = newNonceCache(opts.Clock)
+	}
+	return &SignatureVerifier{opts: opts}
+}
+
+// Verify checks the signature of req, its body is read and replaced.
+func (v *SignatureVerifier) Verify(req *http.Request) error {
+	signature := req.Header.Get(HeaderSignature)
+	timestamp := req.Header.Get(HeaderSignatureTimestamp)
+	nonce := req.Header.Get(HeaderSignatureNonce)
+	if signature == "" || timestamp == "" || nonce == "" {
+		return ErrSignatureMissing
+	}
+	secret, ok := v.opts.Keys[req.Header.Get(HeaderSignatureKeyID)]
+	if !ok {
+		return ErrSignatureUnknownKey
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	signed := time.Unix(unix, 0)
+	now := v.opts.Clock.Now()
+	if signed.Before(now.Add(-v.opts.Skew)) || signed.After(now.Add(v.opts.Skew)) {
+		return ErrSignatureExpired
+	}
+
+	mac, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	body, err := readBody(req, v.opts.MaxBody)
+	if err != nil {
+		return err
+	}
+	if !VerifyHMACSHA256(secret, canonicalRequest(req, timestamp, nonce, body), mac) {
+		return ErrSignatureInvalid
+	}
+	// a nonce is only remembered once its signature is genuine,
+	// for as long as its timestamp is accepted
+	if v.opts.Nonces.Seen(nonce, signed.Add(v.opts.Skew)) {
+		return ErrSignatureReplayed
+	}
+	return nil
+}
+
+// VerifySignature rejects the requests without a valid signature
+// with the unauthorized envelope.
+func VerifySignature(verifier *SignatureVerifier) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := verifier.Verify(r); err != nil {
+				For(r.Context()).Infof("signature verification failed %s %s: %v", r.Method, r.URL.Path, err)
+				Response(r).APIStatusUnauthorized(w, r, err).WriteJSON()
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SigningTransport signs the outbound requests, add it last to
+// ClientOpts.Middlewares so the retries are signed again.
+func SigningTransport(signer *RequestSigner) TransportMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// never alter the request of the caller
+			out := req.Clone(req.Context())
+			if err := signer.Sign(out); err != nil {
+				if req.Body != nil {
+					_ = req.Body.Close()
+				}
+				return nil, fmt.Errorf("sign request: %w", err)
+			}
+			return next.RoundTrip(out)
+		})
+	}
+}
+
+// canonicalRequest is the signed string, one field per line.
+func canonicalRequest(req *http.Request, timestamp, nonce string, body []byte) []byte {
+	digest := sha256.Sum256(body)
+	path := req.URL.EscapedPath()
+	if req.URL.RawQuery != "" {
+		path += "?" + req.URL.RawQuery
+	}
+	return []byte(strings.Join([]string{
+		strings.ToUpper(req.Method),
+		path,
+		timestamp,
+		nonce,
+		hex.EncodeToString(digest[:]),
+	}, "\n"))
+}
+
+// readBody reads the body of req and puts back a copy, limit < 0 is unbounded.
+func readBody(req *http.Request, limit int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	var reader io.Reader = req.Body
+	if limit >= 0 {
+		reader = io.LimitReader(req.Body, limit+1)
+	}
+	body, err := ioutil.ReadAll(reader)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if limit >= 0 && int64(len(body)) > limit {
+		return nil, ErrSignatureBodyTooBig
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, nil
+}
+
+// NewNonceCache returns an in-memory NonceCache, the expired nonces are
+// evicted on the following calls.
+func NewNonceCache() NonceCache {
+	return newNonceCache(SystemClock)
+}
+
+func newNonceCache(clock Clock) *nonceCache {
+	return &nonceCache{
+		nonces: make(map[string]time.Time),
+		clock:  clock,
+	}
+}
+
+type nonceCache struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	clock  Clock
+	sweep  time.Time
+}
+
+func (c *nonceCache) Seen(nonce string, expires time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	if now.After(c.sweep) {
+		for n, exp := range c.nonces {
+			if now.After(exp) {
+				delete(c.nonces, n)
+			}
+		}
+		c.sweep = now.Add(time.Minute)
+	}
+	if exp, ok := c.nonces[nonce]; ok && !now.After(exp) {
+		return true
+	}
+	c.nonces[nonce] = expires
+	return false
+}
@@ -0,0 +1,125 @@
+/*  signature_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 20:50
+ */
+
+package mimir
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestSignature(t *testing.T) {
+	clock := newFakeClock()
+	signer := &RequestSigner{KeyID: "partner", Secret: []byte("secret"), Clock: clock}
+	verifier := NewSignatureVerifier(SignatureVerifierOpts{
+		Keys:  map[string][]byte{"partner": []byte("secret")},
+		Skew:  time.Minute,
+		Clock: clock,
+	})
+
+	signed := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/payment?attempt=1", strings.NewReader(`{"id":1}`))
+		require.NoError(t, signer.Sign(req))
+		return req
+	}
+
+	req := signed()
+	require.NoError(t, verifier.Verify(req))
+	// the body is still readable after the verification
+	body, err := ioutil.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1}`, string(body))
+
+	t.Run("replayed", func(t *testing.T) {
+		req := signed()
+		replay := req.Clone(req.Context())
+		replay.Body, _ = req.GetBody()
+		require.NoError(t, verifier.Verify(req))
+		assert.Equal(t, ErrSignatureReplayed, verifier.Verify(replay))
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		req := signed()
+		req.Body = ioutil.NopCloser(strings.NewReader(`{"id":2}`))
+		assert.Equal(t, ErrSignatureInvalid, verifier.Verify(req))
+	})
+
+	t.Run("tampered path", func(t *testing.T) {
+		req := signed()
+		req.URL.RawQuery = "attempt=2"
+		assert.Equal(t, ErrSignatureInvalid, verifier.Verify(req))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		req := signed()
+		clock.Advance(2 * time.Minute)
+		assert.Equal(t, ErrSignatureExpired, verifier.Verify(req))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		req := signed()
+		req.Header.Set(HeaderSignatureKeyID, "other")
+		assert.Equal(t, ErrSignatureUnknownKey, verifier.Verify(req))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.Equal(t, ErrSignatureMissing, verifier.Verify(req))
+	})
+
+	t.Run("body too big", func(t *testing.T) {
+		small := NewSignatureVerifier(SignatureVerifierOpts{
+			Keys:    map[string][]byte{"partner": []byte("secret")},
+			MaxBody: 4,
+			Clock:   clock,
+		})
+		assert.Equal(t, ErrSignatureBodyTooBig, small.Verify(signed()))
+	})
+}
+
+func TestSignatureMiddlewareAndTransport(t *testing.T) {
+	verifier := NewSignatureVerifier(SignatureVerifierOpts{Keys: map[string][]byte{"": []byte("secret")}})
+	srv := httptest.NewServer(VerifySignature(verifier)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	})))
+	defer srv.Close()
+
+	signing := NewClient(ClientOpts{
+		Middlewares: []TransportMiddleware{SigningTransport(&RequestSigner{Secret: []byte("secret")})},
+	})
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/events", bytes.NewReader([]byte("ping")))
+	require.NoError(t, err)
+	resp, err := signing.Do(req)
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ping", string(body))
+	// the request of the caller is left unsigned
+	assert.Empty(t, req.Header.Get(HeaderSignature))
+
+	resp, err = http.Post(srv.URL+"/events", "text/plain", strings.NewReader("ping"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	wrong := NewClient(ClientOpts{
+		Middlewares: []TransportMiddleware{SigningTransport(&RequestSigner{Secret: []byte("guess")})},
+	})
+	resp, err = wrong.Post(srv.URL+"/events", "text/plain", strings.NewReader("ping"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
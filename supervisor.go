@@ -0,0 +1,372 @@
+/*  supervisor.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 09:12
+ */
+
+package mimir
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultStopTimeout   = 10 * time.Second
+	DefaultReadyInterval = 50 * time.Millisecond
+)
+
+type (
+	// ComponentFunc is a lifecycle hook of a supervised component.
+	ComponentFunc func(context.Context) error
+
+	// Component is a named unit of work run by the Supervisor.
+	// Start blocks until the component stops or its context is cancelled,
+	// Ready reports nil once the component accepts work and Stop releases
+	// its resources within StopTimeout.
+	Component struct {
+		Name        string
+		DependsOn   []string
+		Start       ComponentFunc
+		Ready       ComponentFunc
+		Stop        ComponentFunc
+		StopTimeout time.Duration
+	}
+
+	SupervisorOpts struct {
+		Logger        Logging
+		StopTimeout   time.Duration
+		ReadyInterval time.Duration
+	}
+)
+
+// ComponentError reports the component and the lifecycle phase that failed.
+type ComponentError struct {
+	Name  string
+	Phase string
+	Err   error
+}
+
+func (e *ComponentError) Error() string {
+	return fmt.Sprintf("component %q %s: %v", e.Name, e.Phase, e.Err)
+}
+
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// ComponentErrors aggregates every component failure of a Supervisor run.
+type ComponentErrors []*ComponentError
+
+func (e ComponentErrors) Error() string {
+	msg := make([]string, 0, len(e))
+	for _, err := range e {
+		msg = append(msg, err.Error())
+	}
+	return strings.Join(msg, "; ")
+}
+
+// Failed returns the names of the failed components in order of failure.
+func (e ComponentErrors) Failed() []string {
+	names := make([]string, 0, len(e))
+	for _, err := range e {
+		names = append(names, err.Name)
+	}
+	return names
+}
+
+type componentState struct {
+	Component
+	ready   bool
+	started bool
+	failed  bool
+}
+
+// Supervisor starts registered components in dependency order, cancels all of
+// them when one fails and stops them in reverse order.
+type Supervisor struct {
+	mu         sync.Mutex
+	opts       SupervisorOpts
+	logger     Logging
+	components []*componentState
+	index      map[string]*componentState
+	order      []*componentState
+	errs       ComponentErrors
+	stopping   bool
+}
+
+func NewSupervisor(opts SupervisorOpts) *Supervisor {
+	if opts.Logger == nil {
+		opts.Logger = With(Field("supervisor", "mimir"))
+	}
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = DefaultStopTimeout
+	}
+	if opts.ReadyInterval <= 0 {
+		opts.ReadyInterval = DefaultReadyInterval
+	}
+	return &Supervisor{
+		opts:   opts,
+		logger: opts.Logger,
+		index:  make(map[string]*componentState),
+	}
+}
+
+// Register adds a component, the name must be unique.
+func (s *Supervisor) Register(c Component) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.Name == "" {
+		return fmt.Errorf("component name is required")
+	}
+	if c.Start == nil {
+		return fmt.Errorf("component %q has no start hook", c.Name)
+	}
+	if _, ok := s.index[c.Name]; ok {
+		return fmt.Errorf("component %q already registered", c.Name)
+	}
+	state := &componentState{Component: c}
+	s.components = append(s.components, state)
+	s.index[c.Name] = state
+	return nil
+}
+
+// Run starts every component after its dependencies are ready and blocks until
+// the context is cancelled or a component fails. It matches AppRunner.
+func (s *Supervisor) Run(ctx context.Context) error {
+	order, err := s.resolve()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	fail := func(name, phase string, err error) {
+		s.mu.Lock()
+		s.errs = append(s.errs, &ComponentError{Name: name, Phase: phase, Err: err})
+		s.mu.Unlock()
+		s.logger.Errorf("Component %s failed on %s: %v", name, phase, err)
+		cancel()
+	}
+
+	for _, c := range order {
+		if ctx.Err() != nil {
+			break
+		}
+		c := c // pin it
+		exited := make(chan struct{})
+		s.mu.Lock()
+		c.started = true
+		s.mu.Unlock()
+		s.logger.Infof("Starting component %s", c.Name)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(exited)
+			if err := c.Start(ctx); err != nil && ctx.Err() == nil {
+				s.setFailed(c)
+				fail(c.Name, "start", err)
+			}
+		}()
+
+		if err := s.waitReady(ctx, c, exited); err != nil {
+			fail(c.Name, "ready", err)
+			break
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-done:
+	}
+	cancel()
+	<-done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errs) > 0 {
+		errs := make(ComponentErrors, len(s.errs))
+		copy(errs, s.errs)
+		return errs
+	}
+	return nil
+}
+
+func (s *Supervisor) waitReady(ctx context.Context, c *componentState, exited <-chan struct{}) error {
+	if c.Ready == nil {
+		s.setReady(c, true)
+		return nil
+	}
+	// a failed start cancels ctx before exited is closed
+	ticker := time.NewTicker(s.opts.ReadyInterval)
+	defer ticker.Stop()
+	for {
+		if err := c.Ready(ctx); err == nil {
+			s.setReady(c, true)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-exited:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("exited before ready")
+		case <-ticker.C:
+		}
+	}
+}
+
+// setReady never marks a failed component ready.
+func (s *Supervisor) setReady(c *componentState, ready bool) {
+	s.mu.Lock()
+	c.ready = ready && !c.failed
+	s.mu.Unlock()
+}
+
+func (s *Supervisor) setFailed(c *componentState) {
+	s.mu.Lock()
+	c.failed = true
+	c.ready = false
+	s.mu.Unlock()
+}
+
+// resolve orders the components so that dependencies start first,
+// keeping registration order between independent components.
+func (s *Supervisor) resolve() ([]*componentState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[string]int, len(s.components))
+	order := make([]*componentState, 0, len(s.components))
+
+	var visit func(c *componentState, path []string) error
+	visit = func(c *componentState, path []string) error {
+		switch marks[c.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("component dependency cycle: %s", strings.Join(append(path, c.Name), " -> "))
+		}
+		marks[c.Name] = visiting
+		for _, dep := range c.DependsOn {
+			d, ok := s.index[dep]
+			if !ok {
+				return fmt.Errorf("component %q depends on unknown component %q", c.Name, dep)
+			}
+			if err := visit(d, append(path, c.Name)); err != nil {
+				return err
+			}
+		}
+		marks[c.Name] = visited
+		order = append(order, c)
+		return nil
+	}
+
+	for _, c := range s.components {
+		if err := visit(c, nil); err != nil {
+			return nil, err
+		}
+	}
+	s.order = order
+	return order, nil
+}
+
+// IsReady reports whether every component is ready and the supervisor
+// is not shutting down.
+func (s *Supervisor) IsReady() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopping || len(s.components) == 0 {
+		return false
+	}
+	for _, c := range s.components {
+		if !c.ready {
+			return false
+		}
+	}
+	return true
+}
+
+// ComponentReady reports the readiness of a single component.
+func (s *Supervisor) ComponentReady(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.index[name]
+	return ok && c.ready && !s.stopping
+}
+
+// Shutdown runs the stop hooks of the started components in reverse order,
+// each bounded by its own deadline.
+func (s *Supervisor) Shutdown() error {
+	s.mu.Lock()
+	s.stopping = true
+	order := make([]*componentState, len(s.order))
+	copy(order, s.order)
+	s.mu.Unlock()
+
+	var errs ComponentErrors
+	for i := len(order) - 1; i >= 0; i-- {
+		c := order[i]
+		s.mu.Lock()
+		started := c.started
+		s.mu.Unlock()
+		if !started || c.Stop == nil {
+			continue
+		}
+		timeout := c.StopTimeout
+		if timeout <= 0 {
+			timeout = s.opts.StopTimeout
+		}
+		s.logger.Infof("Stopping component %s", c.Name)
+		if err := s.stop(c, timeout); err != nil {
+			s.logger.Errorf("Component %s failed on stop: %v", c.Name, err)
+			errs = append(errs, &ComponentError{Name: c.Name, Phase: "stop", Err: err})
+		}
+		s.setReady(c, false)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (s *Supervisor) stop(c *componentState, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("stop deadline %s exceeded", timeout)
+	}
+}
+
+// Cleanup shuts the components down, it matches the cleanup func of Application.
+func (s *Supervisor) Cleanup() {
+	_ = s.Shutdown()
+}
@@ -0,0 +1,208 @@
+/*  supervisor_test.go
+*
+* @Date:               October 18, 2026
+* @Last Modified time: 18/10/26 09:40
+ */
+
+package mimir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lifecycleRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *lifecycleRecorder) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *lifecycleRecorder) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+func blockingComponent(name string, rec *lifecycleRecorder, deps ...string) Component {
+	started := make(chan struct{})
+	return Component{
+		Name:      name,
+		DependsOn: deps,
+		Start: func(ctx context.Context) error {
+			rec.add("start " + name)
+			close(started)
+			<-ctx.Done()
+			return fmt.Errorf("%s interrupted through context", name)
+		},
+		Ready: func(ctx context.Context) error {
+			select {
+			case <-started:
+				return nil
+			default:
+				return fmt.Errorf("%s not started", name)
+			}
+		},
+		Stop: func(ctx context.Context) error {
+			rec.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestSupervisorDependencyOrder(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	sup := NewSupervisor(SupervisorOpts{})
+	assert.NoError(t, sup.Register(blockingComponent("http", rec, "db", "cache")))
+	assert.NoError(t, sup.Register(blockingComponent("db", rec)))
+	assert.NoError(t, sup.Register(blockingComponent("cache", rec, "db")))
+	assert.Error(t, sup.Register(blockingComponent("db", rec)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error)
+	go func() {
+		errChan <- sup.Run(ctx)
+	}()
+
+	assert.Eventually(t, sup.IsReady, time.Second, 5*time.Millisecond)
+	cancel()
+	assert.NoError(t, <-errChan)
+
+	assert.NoError(t, sup.Shutdown())
+	assert.False(t, sup.IsReady())
+	assert.Equal(t, []string{
+		"start db", "start cache", "start http",
+		"stop http", "stop cache", "stop db",
+	}, rec.list())
+}
+
+func TestSupervisorComponentFailure(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	sup := NewSupervisor(SupervisorOpts{})
+	assert.NoError(t, sup.Register(blockingComponent("db", rec)))
+	assert.NoError(t, sup.Register(Component{
+		Name:      "grpc",
+		DependsOn: []string{"db"},
+		Start: func(ctx context.Context) error {
+			return fmt.Errorf("failed to listen")
+		},
+	}))
+
+	err := sup.Run(context.Background())
+	assert.Error(t, err)
+
+	var errs ComponentErrors
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.Equal(t, []string{"grpc"}, errs.Failed())
+	}
+	assert.Contains(t, err.Error(), `component "grpc" start: failed to listen`)
+
+	sup.Cleanup()
+	assert.Equal(t, []string{"start db", "stop db"}, rec.list())
+}
+
+func TestSupervisorStartFailureBeforeReady(t *testing.T) {
+	for _, ready := range []ComponentFunc{
+		nil,
+		func(context.Context) error { return fmt.Errorf("not ready") },
+	} {
+		sup := NewSupervisor(SupervisorOpts{ReadyInterval: time.Millisecond})
+		assert.NoError(t, sup.Register(Component{
+			Name: "grpc",
+			Start: func(ctx context.Context) error {
+				return fmt.Errorf("failed to listen")
+			},
+			Ready: ready,
+		}))
+
+		err := sup.Run(context.Background())
+		var errs ComponentErrors
+		if assert.True(t, errors.As(err, &errs)) {
+			// the start error only, no exited before ready
+			assert.Equal(t, []string{"grpc"}, errs.Failed())
+		}
+		assert.False(t, sup.ComponentReady("grpc"))
+	}
+}
+
+func TestSupervisorReadiness(t *testing.T) {
+	var ready bool
+	var mu sync.Mutex
+	sup := NewSupervisor(SupervisorOpts{ReadyInterval: time.Millisecond})
+	assert.NoError(t, sup.Register(Component{
+		Name: "http",
+		Start: func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			ready = true
+			mu.Unlock()
+			<-ctx.Done()
+			return nil
+		},
+		Ready: func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if !ready {
+				return fmt.Errorf("not ready")
+			}
+			return nil
+		},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = sup.Run(ctx)
+	}()
+
+	assert.False(t, sup.ComponentReady("http"))
+	assert.Eventually(t, func() bool {
+		return sup.ComponentReady("http")
+	}, time.Second, time.Millisecond)
+}
+
+func TestSupervisorStopDeadline(t *testing.T) {
+	sup := NewSupervisor(SupervisorOpts{})
+	assert.NoError(t, sup.Register(Component{
+		Name: "worker",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		},
+		StopTimeout: 10 * time.Millisecond,
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go cancel()
+	assert.NoError(t, sup.Run(ctx))
+
+	err := sup.Shutdown()
+	var errs ComponentErrors
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.Equal(t, []string{"worker"}, errs.Failed())
+		assert.Equal(t, "stop", errs[0].Phase)
+	}
+}
+
+func TestSupervisorDependencyCycle(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	sup := NewSupervisor(SupervisorOpts{})
+	assert.NoError(t, sup.Register(blockingComponent("a", rec, "b")))
+	assert.NoError(t, sup.Register(blockingComponent("b", rec, "a")))
+
+	assert.EqualError(t, sup.Run(context.Background()), "component dependency cycle: a -> b -> a")
+}
@@ -19,12 +19,17 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
-func Validate(s interface{}) (errors []ErrorValidator) {
+func newValidator() *validator.Validate {
 	validate := validator.New()
 	_ = validate.RegisterValidation("date", DateValidation)
 	_ = validate.RegisterValidation("datetime", DatetimeValidation)
 	_ = validate.RegisterValidation("daterange", DateRangeValidation)
 	_ = validate.RegisterValidation("enum", ParseTagPayment)
+	return validate
+}
+
+func Validate(s interface{}) (errors []ErrorValidator) {
+	validate := newValidator()
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
 		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
 		if name == "-" {
@@ -48,6 +53,59 @@ func Validate(s interface{}) (errors []ErrorValidator) {
 	return nil
 }
 
+// ValidateConfig checks the validate tags of a configuration struct, on top of
+// the Validate rules it understands port and duration. Fields are reported
+// with their configuration key path, e.g. app.port.
+func ValidateConfig(s interface{}) (errors []ErrorValidator) {
+	validate := newValidator()
+	_ = validate.RegisterValidation("port", PortValidation)
+	_ = validate.RegisterValidation("duration", DurationValidation)
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("mapstructure"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return fld.Name
+		}
+		return name
+	})
+
+	err := validate.Struct(s)
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil
+	}
+	// the namespace starts with the struct type name unless it is anonymous
+	root := reflect.Indirect(reflect.ValueOf(s)).Type().Name()
+	for _, err := range validationErrors {
+		key := err.Namespace()
+		if root != "" {
+			key = strings.TrimPrefix(key, root+".")
+		}
+		key = strings.ToLower(key)
+		errors = append(errors, ErrorValidator{
+			Tag:     err.Tag(),
+			Value:   fmt.Sprintf("%v", err.Value()),
+			Field:   key,
+			Type:    err.Type().String(),
+			Message: fmt.Sprintf("Invalid value %v for config %s", err.Value(), key),
+		})
+	}
+	return errors
+}
+
+// ConfigValidationError lists the configuration keys that failed validation.
+type ConfigValidationError []ErrorValidator
+
+func (e ConfigValidationError) Error() string {
+	msg := make([]string, 0, len(e))
+	for _, err := range e {
+		msg = append(msg, fmt.Sprintf("%s failed on %s (value %q)", err.Field, err.Tag, err.Value))
+	}
+	return "invalid configuration: " + strings.Join(msg, ", ")
+}
+
 func DateValidation(fl validator.FieldLevel) bool {
 	if _, err := time.Parse("2006-01-02", fl.Field().String()); err != nil {
 		return false
@@ -78,6 +136,38 @@ func DateRangeValidation(fl validator.FieldLevel) bool {
 	return true
 }
 
+func PortValidation(fl validator.FieldLevel) bool {
+	var port int64
+	field := fl.Field()
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		port = field.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		port = int64(field.Uint())
+	case reflect.String:
+		p, err := strconv.ParseInt(field.String(), 10, 64)
+		if err != nil {
+			return false
+		}
+		port = p
+	default:
+		return false
+	}
+	return port > 0 && port <= 65535
+}
+
+func DurationValidation(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	switch field.Kind() {
+	case reflect.String:
+		_, err := time.ParseDuration(field.String())
+		return err == nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int() >= 0
+	}
+	return false
+}
+
 func ParseDate(dtStr string) time.Time {
 	date, err := time.Parse("2006-01-02", dtStr)
 	if err != nil {
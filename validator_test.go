@@ -129,3 +129,46 @@ func TestParseDatetime(t *testing.T) {
 	dt = ParseDatetime("2019-09-01T16:18:22Z00:00")
 	assert.Equal(t, time.Time{}, dt)
 }
+
+type configConstantsValidated struct {
+	App struct {
+		Port     int    `mapstructure:"port" validate:"port"`
+		BaseURL  string `mapstructure:"base_url" validate:"url"`
+		Timeout  string `mapstructure:"timeout" validate:"duration"`
+		CertFile string `mapstructure:"cert_file" validate:"omitempty,file"`
+	}
+	DB struct {
+		DsnMain string `mapstructure:"dsn_main" validate:"required"`
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	cfg := configConstantsValidated{}
+	cfg.App.Port = 8778
+	cfg.App.BaseURL = "http://localhost:8778"
+	cfg.App.Timeout = "5s"
+	cfg.App.CertFile = "validator.go"
+	cfg.DB.DsnMain = "host=localhost"
+	assert.Nil(t, ValidateConfig(cfg))
+
+	cfg.App.Port = 0
+	cfg.App.BaseURL = "localhost"
+	cfg.App.Timeout = "5 seconds"
+	cfg.App.CertFile = "cert.pem"
+	cfg.DB.DsnMain = ""
+	errs := ValidateConfig(&cfg)
+
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field+":"+err.Tag)
+	}
+	assert.Equal(t, []string{
+		"app.port:port",
+		"app.base_url:url",
+		"app.timeout:duration",
+		"app.cert_file:file",
+		"db.dsn_main:required",
+	}, fields)
+	assert.Equal(t, "Invalid value 0 for config app.port", errs[0].Message)
+	assert.Contains(t, ConfigValidationError(errs).Error(), `app.port failed on port (value "0")`)
+}